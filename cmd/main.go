@@ -72,9 +72,17 @@ func main() {
 
     // Setup API routes
     log.Info().Msg("Setting up API routes...")
-    api.SetupRoutes(app, redisClient, cfg)
+    handlers := api.SetupRoutes(app, redisClient, cfg)
     log.Info().Msg("API routes setup completed")
 
+    // Process feed files dropped into the feed source directory
+    watchCtx, stopWatching := context.WithCancel(context.Background())
+    defer stopWatching()
+    go handlers.WatchFeedFiles(watchCtx)
+
+    // Keep WebSub subscriptions of push-enabled sources alive
+    go handlers.RunWebSub(watchCtx)
+
     // Start server in a goroutine
     go func() {
         log.Info().Str("port", cfg.Port).Msg("Starting server")
@@ -89,6 +97,7 @@ func main() {
     <-quit
 
     log.Info().Msg("Shutting down server...")
+    stopWatching()
 
     // Create a deadline for graceful shutdown
     ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
@@ -99,5 +108,10 @@ func main() {
         log.Error().Err(err).Msg("Server forced to shutdown")
     }
 
+    // Release the news index
+    if err := handlers.Close(); err != nil {
+        log.Error().Err(err).Msg("Error closing news store")
+    }
+
     log.Info().Msg("Server exited properly")
 }
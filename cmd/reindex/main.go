@@ -0,0 +1,51 @@
+// Command reindex rebuilds the news metadata index from the files on disk.
+// Stop the server first: it holds the index open while running.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/storage"
+)
+
+func main() {
+	cfg := config.Load()
+
+	if err := logger.Init(logger.Config{
+		Level:  cfg.LogLevel,
+		Output: "stdout",
+		Pretty: true,
+	}); err != nil {
+		panic(err)
+	}
+	log := logger.Get()
+
+	if cfg.StorageIndexPath == "" {
+		log.Fatal().Msg("STORAGE_INDEX_PATH is empty, there is no index to rebuild")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := storage.NewFileStore(cfg.ProcessedPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open news store")
+	}
+	idx, err := storage.OpenIndex(cfg.StorageIndexPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open index, is the server still running?")
+	}
+	defer idx.Close()
+	store.SetIndex(idx)
+
+	n, err := store.Reindex(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to rebuild index")
+	}
+	log.Info().Int("items", n).Str("index", cfg.StorageIndexPath).Msg("Index rebuilt")
+}
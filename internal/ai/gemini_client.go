@@ -4,19 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/bilgisen/goen/internal/config"
 	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
 	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
 )
 
 type GeminiClient struct {
-	client  *resty.Client
-	apiKey  string
-	model   string
-	baseURL string
+	client     *resty.Client
+	apiKey     string
+	model      string
+	baseURL    string
+	categories *config.Categories
 }
 
 type geminiRequest struct {
@@ -44,17 +48,62 @@ type geminiResponse struct {
 	} `json:"error"`
 }
 
-func NewGeminiClient(apiKey, model string) *GeminiClient {
+// NewGeminiClient creates a client that asks the model to pick one of the
+// given categories; a nil taxonomy lets the model keep the original category
+func NewGeminiClient(apiKey, model string, categories *config.Categories) *GeminiClient {
 	return &GeminiClient{
-		client:  resty.New().SetTimeout(60 * time.Second),
-		apiKey:  apiKey,
-		model:   model,
-		baseURL: "https://generativelanguage.googleapis.com/v1beta/models",
+		client:     resty.New().SetTimeout(60 * time.Second),
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    "https://generativelanguage.googleapis.com/v1beta/models",
+		categories: categories,
 	}
 }
 
+// SetBaseURL points the client at another endpoint serving the Gemini API,
+// e.g. a proxy
+func (g *GeminiClient) SetBaseURL(baseURL string) *GeminiClient {
+	g.baseURL = strings.TrimRight(baseURL, "/")
+	return g
+}
+
+// GenerateOptions override how a single item is generated
+type GenerateOptions struct {
+	// Model replaces the model of the client
+	Model string
+	// Prompt replaces the standard prompt. It is a template executed with
+	// PromptData and must still ask for the JSON fields of ResponseTemplate.
+	Prompt string
+	// ExtraInstructions are appended to the prompt under "Additional
+	// instructions", e.g. to ask for a more neutral headline
+	ExtraInstructions string
+}
+
+// modelName matches Gemini model names such as gemini-2.0-flash
+var modelName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// ValidModel reports whether name looks like a model name
+func ValidModel(name string) bool {
+	return len(name) <= 64 && modelName.MatchString(name)
+}
+
 // GenerateEnglishNews processes a Turkish news item and returns an English version
 func (g *GeminiClient) GenerateEnglishNews(ctx context.Context, item models.FeedItem) (*models.NewsItem, error) {
+	return g.GenerateEnglishNewsWith(ctx, item, GenerateOptions{})
+}
+
+// GenerateEnglishNewsWith is GenerateEnglishNews with another model, prompt
+// or extra prompt instructions. Items written with another prompt or
+// instructions record a prompt version derived from them.
+func (g *GeminiClient) GenerateEnglishNewsWith(ctx context.Context, item models.FeedItem, opts GenerateOptions) (*models.NewsItem, error) {
+	model := g.model
+	if opts.Model != "" {
+		if !ValidModel(opts.Model) {
+			return nil, fmt.Errorf("invalid model name %q", opts.Model)
+		}
+		model = opts.Model
+	}
+
 	log := logger.Get()
 	log.Info().
 		Str("guid", item.Guid).
@@ -66,14 +115,23 @@ func (g *GeminiClient) GenerateEnglishNews(ctx context.Context, item models.Feed
 	defer cancel()
 
 	// Build the prompt
-	prompt := buildPrompt(item)
+	prompt := buildPrompt(item, g.categories)
+	if opts.Prompt != "" {
+		var err error
+		if prompt, err = customPrompt(opts.Prompt, item, g.categories); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ExtraInstructions != "" {
+		prompt += "\n\nAdditional instructions:\n" + strings.TrimSpace(opts.ExtraInstructions)
+	}
 	log.Debug().
 		Str("guid", item.Guid).
 		Msg("Built prompt for Gemini API")
 
 	// Call the Gemini API
 	startTime := time.Now()
-	response, err := g.callGeminiAPI(ctx, prompt)
+	response, err := g.callGeminiAPI(ctx, model, prompt)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -97,6 +155,8 @@ func (g *GeminiClient) GenerateEnglishNews(ctx context.Context, item models.Feed
 			Msg("Error parsing Gemini response")
 		return nil, fmt.Errorf("error parsing Gemini response: %w", err)
 	}
+	newsItem.Model = model
+	newsItem.PromptVersion = promptVersion(opts.Prompt, opts.ExtraInstructions)
 
 	log.Info().
 		Str("guid", item.Guid).
@@ -106,12 +166,12 @@ func (g *GeminiClient) GenerateEnglishNews(ctx context.Context, item models.Feed
 	return newsItem, nil
 }
 
-func (g *GeminiClient) callGeminiAPI(ctx context.Context, prompt string) (string, error) {
+func (g *GeminiClient) callGeminiAPI(ctx context.Context, model, prompt string) (string, error) {
 	log := logger.Get()
-	url := fmt.Sprintf("%s/%s:generateContent?key=%s", g.baseURL, g.model, g.apiKey)
+	url := fmt.Sprintf("%s/%s:generateContent?key=%s", g.baseURL, model, g.apiKey)
 	
 	log.Debug().
-		Str("model", g.model).
+		Str("model", model).
 		Msg("Sending request to Gemini API")
 
 	req := geminiRequest{
@@ -166,7 +226,13 @@ func (g *GeminiClient) callGeminiAPI(ctx context.Context, prompt string) (string
 	return result.Candidates[0].Content.Parts[0].Text, nil
 }
 
-func buildPrompt(item models.FeedItem) string {
+func buildPrompt(item models.FeedItem, categories *config.Categories) string {
+	categoryRule, categoryField := "Category (from the original)", "category (from original)"
+	if categories != nil {
+		categoryRule = "Category: exactly one slug from the allowed categories below"
+		categoryField = "category (one allowed slug)"
+	}
+
 	return fmt.Sprintf(`You are an expert English journalist and SEO writer. 
 Transform this Turkish news article into a professional English version with the following structure:
 
@@ -174,7 +240,7 @@ Transform this Turkish news article into a professional English version with the
 2. SEO description (max 160 characters)
 3. TLDR (3 bullet points)
 4. Main content in markdown format
-5. Category (from the original)
+5. %s
 6. Tags (5-7 relevant keywords)
 7. Image title and description (for accessibility)
 
@@ -183,7 +249,7 @@ Respond in valid JSON format with these fields:
 - seo_description
 - tldr (array of strings)
 - content_md (markdown formatted)
-- category (from original)
+- %s
 - tags (array of strings)
 - image_title
 - image_description
@@ -193,10 +259,56 @@ Title: %s
 
 Content: %s
 
-Category: %s`, 
-		escapeJSON(item.TitleTR), 
-		escapeJSON(item.ContentTR), 
-		escapeJSON(item.Category))
+Category: %s%s`,
+		categoryRule,
+		categoryField,
+		escapeJSON(item.TitleTR),
+		escapeContent(item.ContentTR),
+		escapeJSON(item.Category),
+		categoryList(item, categories))
+}
+
+// categoryList lists the categories the model may choose from, pointing at
+// the one the source category already maps to
+// customPrompt executes a prompt template replacing the standard prompt
+func customPrompt(text string, item models.FeedItem, categories *config.Categories) (string, error) {
+	tmpl, err := ParsePrompt(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, PromptData{
+		Title:      escapeJSON(item.TitleTR),
+		Content:    escapeContent(item.ContentTR),
+		Category:   escapeJSON(item.Category),
+		Categories: strings.TrimSpace(categoryList(item, categories)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build prompt: %w", err)
+	}
+	return b.String(), nil
+}
+
+func categoryList(item models.FeedItem, categories *config.Categories) string {
+	if categories == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\nAllowed categories (slug: name):\n")
+	for _, cat := range categories.List {
+		fmt.Fprintf(&b, "- %s: %s", cat.Slug, cat.Name)
+		if parent, ok := categories.Get(cat.Parent); ok {
+			fmt.Fprintf(&b, " (under %s)", parent.Name)
+		}
+		b.WriteString("\n")
+	}
+	if _, ok := categories.Get(item.CategorySlug); ok {
+		fmt.Fprintf(&b, "\nThe original category maps to %q; use that slug.", item.CategorySlug)
+	} else {
+		b.WriteString("\nIf none of them fits, answer with an empty category.")
+	}
+	return b.String()
 }
 
 func parseGeminiResponse(response string, item models.FeedItem) (*models.NewsItem, error) {
@@ -222,21 +334,32 @@ func parseGeminiResponse(response string, item models.FeedItem) (*models.NewsIte
 		return nil, fmt.Errorf("failed to parse response: %w\nResponse: %s", err, cleanResponse)
 	}
 
-	// Create and return the news item
+	// A category the source already maps wins over the model's choice
+	category := result.Category
+	if item.CategorySlug != "" {
+		category = item.CategorySlug
+	}
+
+	// Create and return the news item; the pipeline decides whether it is
+	// published right away or waits for review
 	return &models.NewsItem{
-		ID:          generateID(),
-		SourceGuid:  item.Guid,
-		SeoTitle:    result.SeoTitle,
-		SeoDesc:     result.SeoDesc,
-		TLDR:        result.TLDR,
-		ContentMD:   result.ContentMD,
-		Category:    result.Category,
-		Tags:        result.Tags,
-		Image:       item.Image,
-		ImageTitle:  result.ImageTitle,
-		ImageDesc:   result.ImageDesc,
-		OriginalUrl: item.Url, // Using the actual URL from the feed item
-		CreatedAt:   time.Now(),
+		ID:           generateID(),
+		SourceGuid:   item.Guid,
+		Source:       item.Source,
+		Language:     models.DefaultLanguage,
+		SeoTitle:     result.SeoTitle,
+		SeoDesc:      result.SeoDesc,
+		TLDR:         result.TLDR,
+		ContentMD:    result.ContentMD,
+		Category:     category,
+		Tags:         result.Tags,
+		Image:        item.Image,
+		ImageTitle:   result.ImageTitle,
+		ImageDesc:    result.ImageDesc,
+		OriginalUrl:  item.Url, // Using the actual URL from the feed item
+		CanonicalUrl: item.CanonicalUrl,
+		Flags:        append([]string(nil), item.Flags...),
+		CreatedAt:    time.Now(),
 	}, nil
 }
 
@@ -247,7 +370,16 @@ func escapeJSON(s string) string {
 	return s
 }
 
+// escapeContent escapes quotes but keeps paragraph and list line breaks
+func escapeContent(s string) string {
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\t", " ")
+	return s
+}
+
+// generateID returns a time-ordered UUID (version 7), so IDs stay unique
+// across processes and still sort by creation time
 func generateID() string {
-	// In a real application, you might want to use UUID or another unique ID generator
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	return uuid.Must(uuid.NewV7()).String()
 }
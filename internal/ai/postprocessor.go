@@ -1,25 +1,41 @@
 package ai
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
 )
 
+// ErrUnmappedCategory is returned for generated items whose category is not
+// in the taxonomy when unmapped categories are rejected
+var ErrUnmappedCategory = errors.New("category is not in the taxonomy")
+
 type PostProcessor struct {
 	maxTitleLength       int
 	maxDescriptionLength int
 	minContentLength     int
+	categories           *config.Categories
+	unmapped             string
 }
 
-func NewPostProcessor() *PostProcessor {
+// NewPostProcessor creates a post-processor that maps categories onto the
+// taxonomy. Unmapped categories are rejected or moved to the review bucket,
+// depending on unmapped; a nil taxonomy accepts any category.
+func NewPostProcessor(categories *config.Categories, unmapped string) *PostProcessor {
 	return &PostProcessor{
 		maxTitleLength:       60,
 		maxDescriptionLength: 160,
 		minContentLength:     50,
+		categories:           categories,
+		unmapped:             unmapped,
 	}
 }
 
@@ -36,11 +52,7 @@ func (p *PostProcessor) ProcessNewsItem(item *models.NewsItem) error {
 		return fmt.Errorf("content too short, minimum %d characters required", p.minContentLength)
 	}
 
-	// Clean and trim fields
-	item.SeoTitle = p.cleanText(item.SeoTitle)
-	item.SeoDesc = p.cleanText(item.SeoDesc)
-	item.ContentMD = p.cleanMarkdown(item.ContentMD)
-	item.Image = strings.TrimSpace(item.Image)
+	p.clean(item)
 
 	// Truncate if necessary
 	if len(item.SeoTitle) > p.maxTitleLength {
@@ -50,12 +62,16 @@ func (p *PostProcessor) ProcessNewsItem(item *models.NewsItem) error {
 		item.SeoDesc = item.SeoDesc[:p.maxDescriptionLength-3] + "..."
 	}
 
-	// Ensure required fields have values
-	if item.Category == "" {
-		item.Category = "General"
+	if err := p.resolveCategory(item); err != nil {
+		return err
 	}
+
+	// Ensure required fields have values
 	if len(item.Tags) == 0 {
-		item.Tags = []string{"news", item.Category}
+		item.Tags = []string{"news"}
+		if item.Category != config.CategoryReview {
+			item.Tags = append(item.Tags, item.Category)
+		}
 	}
 
 	// Set timestamps
@@ -68,6 +84,114 @@ func (p *PostProcessor) ProcessNewsItem(item *models.NewsItem) error {
 	return nil
 }
 
+// FieldErrors maps the JSON names of invalid fields to what is wrong with them
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field, msg := range e {
+		fields = append(fields, field+": "+msg)
+	}
+	sort.Strings(fields)
+	return "invalid fields: " + strings.Join(fields, "; ")
+}
+
+// ValidateEdit cleans an edited news item with the rules applied to
+// generated ones. Instead of truncating fields or moving the item to review
+// it reports every broken rule as FieldErrors, so editors can fix them.
+func (p *PostProcessor) ValidateEdit(item *models.NewsItem) error {
+	p.clean(item)
+	errs := FieldErrors{}
+
+	switch n := utf8.RuneCountInString(item.SeoTitle); {
+	case n == 0:
+		errs["seo_title"] = "required"
+	case n > p.maxTitleLength:
+		errs["seo_title"] = fmt.Sprintf("must be at most %d characters", p.maxTitleLength)
+	}
+	switch n := utf8.RuneCountInString(item.SeoDesc); {
+	case n == 0:
+		errs["seo_description"] = "required"
+	case n > p.maxDescriptionLength:
+		errs["seo_description"] = fmt.Sprintf("must be at most %d characters", p.maxDescriptionLength)
+	}
+	if len(strings.TrimSpace(item.ContentMD)) < p.minContentLength {
+		errs["content_md"] = fmt.Sprintf("must be at least %d characters", p.minContentLength)
+	}
+
+	switch {
+	case item.Category == "":
+		errs["category"] = "required"
+	case item.Category == config.CategoryReview && item.CurrentStatus() == models.StatusPublished:
+		// Only held items may wait in the review bucket
+		errs["category"] = "published news needs a category of the taxonomy"
+	case p.categories != nil && item.Category != config.CategoryReview:
+		if slug, ok := p.categories.Resolve(item.Category); ok {
+			item.Category = slug
+			item.CategoryCandidate = ""
+		} else {
+			errs["category"] = "not in the taxonomy"
+		}
+	}
+
+	item.TLDR = cleanList(item.TLDR, p.cleanText)
+	item.Tags = cleanList(item.Tags, p.cleanText)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// clean normalizes the text fields of an item
+func (p *PostProcessor) clean(item *models.NewsItem) {
+	item.SeoTitle = p.cleanText(item.SeoTitle)
+	item.SeoDesc = p.cleanText(item.SeoDesc)
+	item.ContentMD = p.cleanMarkdown(item.ContentMD)
+	item.Image = strings.TrimSpace(item.Image)
+}
+
+// cleanList cleans every value and drops the empty ones
+func cleanList(values []string, clean func(string) string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = clean(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// resolveCategory replaces the category with its taxonomy slug
+func (p *PostProcessor) resolveCategory(item *models.NewsItem) error {
+	if p.categories == nil {
+		if item.Category == "" {
+			item.Category = "General"
+		}
+		return nil
+	}
+	if item.Category == config.CategoryReview {
+		return nil
+	}
+
+	if slug, ok := p.categories.Resolve(item.Category); ok {
+		item.Category = slug
+		item.CategoryCandidate = ""
+		return nil
+	}
+	if p.unmapped == config.UnmappedReject {
+		return fmt.Errorf("%w: %q", ErrUnmappedCategory, item.Category)
+	}
+
+	logger.Get().Warn().
+		Str("id", item.ID).
+		Str("category", item.Category).
+		Msg("Category is not in the taxonomy, moving item to review")
+	item.CategoryCandidate = item.Category
+	item.Category = config.CategoryReview
+	return nil
+}
+
 // cleanText removes unwanted characters and normalizes whitespace
 func (p *PostProcessor) cleanText(s string) string {
 	// Remove control characters
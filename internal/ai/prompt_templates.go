@@ -2,9 +2,55 @@ package ai
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"text/template"
+
+	"github.com/bilgisen/goen/internal/utils"
 )
 
+// PromptVersion identifies the prompt generated items were written with.
+// Bump it whenever PromptTemplates.NewsArticle or buildPrompt changes.
+const PromptVersion = "1"
+
+// promptVersion returns the version of the prompt an item was written with.
+// A replaced prompt is "custom-" and a hash of its template, and extra
+// instructions add a hash of their own, e.g. "1+3f2a9c1e", so items written
+// with the same prompt and instructions compare equal.
+func promptVersion(prompt, instructions string) string {
+	version := PromptVersion
+	if prompt = strings.TrimSpace(prompt); prompt != "" {
+		version = "custom-" + utils.Hash(prompt)[:8]
+	}
+	instructions = strings.TrimSpace(instructions)
+	if instructions == "" {
+		return version
+	}
+	return version + "+" + utils.Hash(instructions)[:8]
+}
+
+// PromptData is what a prompt template replacing the standard prompt is
+// executed with, e.g. "Title: {{.Title}}"
+type PromptData struct {
+	Title      string // title of the source item
+	Content    string // content of the source item
+	Category   string // category of the source item
+	Categories string // allowed categories, empty without a taxonomy
+}
+
+// ParsePrompt parses a prompt template and checks that it only uses the
+// fields of PromptData
+func ParsePrompt(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Parse(text)
+	if err == nil {
+		err = tmpl.Execute(io.Discard, PromptData{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
 // PromptTemplates contains various prompt templates for different types of content generation
 var PromptTemplates = struct {
 	NewsArticle string
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/bilgisen/goen/internal/ai"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/bilgisen/goen/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newsEdit holds the editable fields of a news item. Fields left out of a
+// PATCH body stay nil and keep their value.
+type newsEdit struct {
+	SeoTitle   *string   `json:"seo_title"`
+	SeoDesc    *string   `json:"seo_description"`
+	TLDR       *[]string `json:"tldr"`
+	ContentMD  *string   `json:"content_md"`
+	Category   *string   `json:"category"`
+	Tags       *[]string `json:"tags"`
+	Image      *string   `json:"image"`
+	ImageTitle *string   `json:"image_title"`
+	ImageDesc  *string   `json:"image_desc"`
+}
+
+// apply sets the given fields on item and returns their names
+func (e *newsEdit) apply(item *models.NewsItem) []string {
+	var fields []string
+	set := func(name string, dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+			fields = append(fields, name)
+		}
+	}
+	setList := func(name string, dst *[]string, src *[]string) {
+		if src != nil {
+			*dst = *src
+			fields = append(fields, name)
+		}
+	}
+	set("seo_title", &item.SeoTitle, e.SeoTitle)
+	set("seo_description", &item.SeoDesc, e.SeoDesc)
+	setList("tldr", &item.TLDR, e.TLDR)
+	set("content_md", &item.ContentMD, e.ContentMD)
+	set("category", &item.Category, e.Category)
+	setList("tags", &item.Tags, e.Tags)
+	set("image", &item.Image, e.Image)
+	set("image_title", &item.ImageTitle, e.ImageTitle)
+	set("image_desc", &item.ImageDesc, e.ImageDesc)
+	return fields
+}
+
+// clearEditable empties the editable fields of item, so a PUT replaces them all
+func clearEditable(item *models.NewsItem) {
+	item.SeoTitle, item.SeoDesc, item.ContentMD = "", "", ""
+	item.TLDR, item.Tags = nil, nil
+	item.Category, item.CategoryCandidate = "", ""
+	item.Image, item.ImageTitle, item.ImageDesc = "", "", ""
+}
+
+// etag returns a strong entity tag of the stored state of an item
+func etag(item *models.NewsItem) string {
+	content := *item
+	content.FilePath = ""
+	data, _ := json.Marshal(&content)
+	return `"` + utils.Hash(string(data))[:32] + `"`
+}
+
+// PatchNews handles PATCH /api/v1/admin/news/:id. It changes the fields in
+// the body; the request must send the item's ETag in If-Match.
+func (h *Handlers) PatchNews(c *fiber.Ctx) error {
+	return h.editNews(c, false)
+}
+
+// ReplaceNews handles PUT /api/v1/admin/news/:id. It replaces every
+// editable field; fields left out of the body are cleared.
+func (h *Handlers) ReplaceNews(c *fiber.Ctx) error {
+	return h.editNews(c, true)
+}
+
+func (h *Handlers) editNews(c *fiber.Ctx, replace bool) error {
+	// Only editable fields are accepted, so a typo or an attempt to change
+	// the ID fails loudly instead of being ignored
+	var edit newsEdit
+	dec := json.NewDecoder(bytes.NewReader(c.Body()))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&edit); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body: " + err.Error(),
+		})
+	}
+
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header with the item's ETag is required",
+		})
+	}
+
+	id := c.Params("id")
+	current, err := h.store.Get(c.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "News not found",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error getting news item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get news item",
+		})
+	}
+	if ifMatch != "*" && !matchesETag(ifMatch, etag(current)) {
+		c.Set(fiber.HeaderETag, etag(current))
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "News item was changed since it was read",
+		})
+	}
+
+	next := *current
+	if replace {
+		clearEditable(&next)
+	}
+	fields := edit.apply(&next)
+	if len(fields) == 0 && !replace {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No fields to change",
+		})
+	}
+
+	if h.postProc != nil {
+		var fieldErrs ai.FieldErrors
+		if err := h.postProc.ValidateEdit(&next); errors.As(err, &fieldErrs) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":  "Invalid fields",
+				"fields": fieldErrs,
+			})
+		}
+	}
+
+	note := "replaced all fields"
+	if !replace {
+		sort.Strings(fields)
+		note = "edited " + strings.Join(fields, ", ")
+	}
+	err = h.revise(c.Context(), current, &next, c.Get(editorHeader, defaultEditor), note)
+	if errors.Is(err, storage.ErrConflict) {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "News item was changed since it was read",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error updating news item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update news item",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, etag(&next))
+	return c.JSON(&next)
+}
+
+// matchesETag reports whether an If-Match header lists tag
+func matchesETag(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == tag {
+			return true
+		}
+	}
+	return false
+}
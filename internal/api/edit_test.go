@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newEditApp serves the admin read and edit routes of h
+func newEditApp(h *Handlers) *fiber.App {
+	app := fiber.New()
+	app.Get("/news/:id", h.GetNewsAdmin)
+	app.Patch("/news/:id", h.PatchNews)
+	app.Put("/news/:id", h.ReplaceNews)
+	return app
+}
+
+// sendEdit sends an edit request and decodes the response body into out
+func sendEdit(t *testing.T, app *fiber.App, method, path, ifMatch, body string, out interface{}) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if ifMatch != "" {
+		req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response of %s %s: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+}
+
+func TestPatchNewsChangesOnlyGivenFields(t *testing.T) {
+	h := newTestHandlers(t)
+	app := newEditApp(h)
+	saveTestItem(t, h, "1001")
+
+	status, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil)
+	if status != fiber.StatusOK || tag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d, %q", status, tag)
+	}
+
+	var item models.NewsItem
+	status, patched := sendEdit(t, app, "PATCH", "/news/1001", tag, `{"seo_title": "Edited title"}`, &item)
+	if status != fiber.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if item.SeoTitle != "Edited title" || item.SeoDesc != "The original description" || len(item.Tags) != 1 {
+		t.Errorf("Expected only the title to change, got %+v", item)
+	}
+	if item.Revision != 2 {
+		t.Errorf("Expected revision 2, got %d", item.Revision)
+	}
+
+	// The ETag of the response is the one a later GET returns
+	if status, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil); status != fiber.StatusOK || tag != patched {
+		t.Errorf("Expected GET to return ETag %s, got %d, %s", patched, status, tag)
+	}
+}
+
+func TestReplaceNewsClearsMissingFields(t *testing.T) {
+	h := newTestHandlers(t)
+	app := newEditApp(h)
+	saveTestItem(t, h, "1001")
+	_, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil)
+
+	var item models.NewsItem
+	body := `{
+		"seo_title": "Replaced title",
+		"seo_description": "A replaced description",
+		"content_md": "The replaced article text is long enough to pass the checks.",
+		"category": "sports"
+	}`
+	if status, _ := sendEdit(t, app, "PUT", "/news/1001", tag, body, &item); status != fiber.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if item.SeoTitle != "Replaced title" || item.Category != "sports" {
+		t.Errorf("Expected the replaced fields, got %+v", item)
+	}
+	if len(item.Tags) != 0 || len(item.TLDR) != 0 {
+		t.Errorf("Expected fields left out of the body to be cleared, got tags %v, tldr %v", item.Tags, item.TLDR)
+	}
+}
+
+func TestEditNewsRequiresMatchingETag(t *testing.T) {
+	h := newTestHandlers(t)
+	app := newEditApp(h)
+	saveTestItem(t, h, "1001")
+	_, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil)
+
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1001", "", `{"seo_title": "Edited"}`, nil); status != fiber.StatusPreconditionRequired {
+		t.Errorf("Expected 428 without If-Match, got %d", status)
+	}
+	status, current := sendEdit(t, app, "PATCH", "/news/1001", `"stale"`, `{"seo_title": "Edited"}`, nil)
+	if status != fiber.StatusPreconditionFailed || current != tag {
+		t.Errorf("Expected 412 with the current ETag %s, got %d, %s", tag, status, current)
+	}
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1001", `"stale", `+tag, `{"seo_title": "Edited"}`, nil); status != fiber.StatusOK {
+		t.Errorf("Expected 200 when If-Match lists the ETag, got %d", status)
+	}
+
+	// The first edit changed the item, so its old ETag no longer matches
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1001", tag, `{"seo_title": "Edited again"}`, nil); status != fiber.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for the ETag before the edit, got %d", status)
+	}
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1001", "*", `{"seo_title": "Edited again"}`, nil); status != fiber.StatusOK {
+		t.Errorf("Expected 200 for If-Match: *, got %d", status)
+	}
+}
+
+func TestEditNewsReportsFieldErrors(t *testing.T) {
+	h := newTestHandlers(t)
+	app := newEditApp(h)
+	saveTestItem(t, h, "1001")
+	_, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil)
+
+	var resp struct {
+		Fields map[string]string `json:"fields"`
+	}
+	body := `{"seo_title": "", "content_md": "Too short", "category": "Magazin"}`
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1001", tag, body, &resp); status != fiber.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d", status)
+	}
+	for _, field := range []string{"seo_title", "content_md", "category"} {
+		if resp.Fields[field] == "" {
+			t.Errorf("Expected an error for %s, got %v", field, resp.Fields)
+		}
+	}
+	if _, ok := resp.Fields["seo_description"]; ok {
+		t.Errorf("Expected no error for the unchanged description, got %v", resp.Fields)
+	}
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1001", tag, `{"id": "2002"}`, nil); status != fiber.StatusBadRequest {
+		t.Errorf("Expected 400 for a field that cannot be edited, got %d", status)
+	}
+
+	// Nothing was stored, so the ETag still matches
+	if status, current := sendEdit(t, app, "GET", "/news/1001", "", "", nil); status != fiber.StatusOK || current != tag {
+		t.Errorf("Expected the ETag to be unchanged, got %d, %s", status, current)
+	}
+}
+
+func TestEditNewsResolvesCategory(t *testing.T) {
+	h := newTestHandlers(t)
+	app := newEditApp(h)
+	saveTestItem(t, h, "1001")
+
+	for _, tc := range []struct {
+		category string
+		want     string
+	}{
+		{"Spor", "sports"},
+		{"ECONOMY", "economy"},
+		{"Sports", "sports"},
+	} {
+		_, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil)
+		var item models.NewsItem
+		status, _ := sendEdit(t, app, "PATCH", "/news/1001", tag, `{"category": "`+tc.category+`"}`, &item)
+		if status != fiber.StatusOK || item.Category != tc.want {
+			t.Errorf("Expected %q to resolve to %s, got %d, %q", tc.category, tc.want, status, item.Category)
+		}
+	}
+}
+
+func TestEditNewsKeepsReviewCategoryOffPublishedItems(t *testing.T) {
+	h := newTestHandlers(t)
+	app := newEditApp(h)
+	saveTestItem(t, h, "1001")
+	_, tag := sendEdit(t, app, "GET", "/news/1001", "", "", nil)
+
+	var resp struct {
+		Fields map[string]string `json:"fields"`
+	}
+	for _, method := range []string{"PATCH", "PUT"} {
+		body := `{"seo_title": "Original title", "seo_description": "The original description",
+			"content_md": "The original article text is long enough to pass the checks.", "category": "review"}`
+		if status, _ := sendEdit(t, app, method, "/news/1001", tag, body, &resp); status != fiber.StatusUnprocessableEntity || resp.Fields["category"] == "" {
+			t.Errorf("Expected %s to the review category to fail with a category error, got %d, %v", method, status, resp.Fields)
+		}
+	}
+	if got, _ := h.store.Get(context.Background(), "1001"); got == nil || got.Category != "economy" || got.CurrentStatus() != models.StatusPublished {
+		t.Errorf("Expected the published item to keep its category, got %+v", got)
+	}
+
+	// Held items wait in the review bucket, so editors can still fix their other fields
+	held := saveTestItem(t, h, "1002")
+	held.Category = config.CategoryReview
+	held.Status = models.StatusInReview
+	if err := h.store.Update(context.Background(), held, ""); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	_, tag = sendEdit(t, app, "GET", "/news/1002", "", "", nil)
+	if status, _ := sendEdit(t, app, "PATCH", "/news/1002", tag, `{"seo_title": "Fixed title"}`, nil); status != fiber.StatusOK {
+		t.Errorf("Expected 200 for an edit of a held item, got %d", status)
+	}
+}
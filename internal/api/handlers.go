@@ -2,24 +2,23 @@ package api
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/bilgisen/goen/internal/ai"
 	"github.com/bilgisen/goen/internal/cache"
+	"github.com/bilgisen/goen/internal/cluster"
 	"github.com/bilgisen/goen/internal/config"
 	"github.com/bilgisen/goen/internal/feed"
+	"github.com/bilgisen/goen/internal/ingest"
 	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/search"
 	"github.com/bilgisen/goen/internal/storage"
 	"github.com/gofiber/fiber/v2"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 )
 
 // min returns the minimum of two integers
@@ -31,67 +30,23 @@ func min(a, b int) int {
 }
 
 type Handlers struct {
-	config    *config.Config
-	redis     cache.RedisInterface
-	storage   *storage.Storage
-	processor *feed.Processor
-	gemini    *ai.GeminiClient
-	postProc  *ai.PostProcessor
-	r2Client  *R2Client
+	config      *config.Config
+	redis       cache.RedisInterface
+	store       storage.Store
+	processor   *feed.Processor
+	gemini      *ai.GeminiClient
+	postProc    *ai.PostProcessor
+	clusterer   *cluster.Clusterer
+	searchIndex *search.Index
+	jobs        *jobStore
+	subscriber  *ingest.Subscriber
 }
 
-type R2Client struct {
-	s3Client *s3.Client
-	bucket   string
-}
-
-func NewR2Client(cfg *config.Config) (*R2Client, error) {
-	if cfg.R2Endpoint == "" || cfg.R2AccessKey == "" || cfg.R2SecretKey == "" || cfg.R2Bucket == "" {
-		return nil, fmt.Errorf("R2 configuration is incomplete")
-	}
-
-	customCfg, err := awsConfig.LoadDefaultConfig(context.TODO(),
-		awsConfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			cfg.R2AccessKey, cfg.R2SecretKey, "")),
-		awsConfig.WithRegion("auto"),
-		awsConfig.WithEndpointResolver(aws.EndpointResolverFunc(
-			func(service, region string) (aws.Endpoint, error) {
-				return aws.Endpoint{URL: cfg.R2Endpoint, HostnameImmutable: true}, nil
-			})),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load R2 config: %w", err)
-	}
-
-	return &R2Client{
-		s3Client: s3.NewFromConfig(customCfg),
-		bucket:   cfg.R2Bucket,
-	}, nil
-}
-
-func (r *R2Client) SaveNewsToR2(ctx context.Context, newsItem *models.NewsItem) error {
-	// Marshal news item to JSON
-	jsonData, err := json.MarshalIndent(newsItem, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal news item: %w", err)
-	}
-
-	// Upload to R2
-	_, err = r.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(r.bucket),
-		Key:         aws.String(fmt.Sprintf("processed/%s.json", newsItem.ID)),
-		Body:        strings.NewReader(string(jsonData)),
-		ContentType: aws.String("application/json"),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to upload to R2: %w", err)
-	}
-
-	return nil
-}
+// relatedLimit is the maximum number of related articles returned with a news item
+const relatedLimit = 10
 
 func NewHandlers(cfg *config.Config, redis cache.RedisInterface) (*Handlers, error) {
-	storage, err := storage.NewStorage(cfg.ProcessedPath)
+	store, err := storage.Open(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -99,54 +54,77 @@ func NewHandlers(cfg *config.Config, redis cache.RedisInterface) (*Handlers, err
 	// Initialize Gemini client (optional for basic functionality)
 	var gemini *ai.GeminiClient
 	if cfg.AIApiKey != "" && cfg.AIApiKey != "test-key" {
-		gemini = ai.NewGeminiClient(cfg.AIApiKey, cfg.AIModel)
+		gemini = ai.NewGeminiClient(cfg.AIApiKey, cfg.AIModel, cfg.Categories)
 	}
 
-	// Initialize R2 client (optional)
-	var r2Client *R2Client
-	if cfg.R2Endpoint != "" && cfg.R2AccessKey != "" && cfg.R2SecretKey != "" {
-		logger.Get().Info().
-			Str("r2_endpoint", cfg.R2Endpoint).
-			Str("r2_bucket", cfg.R2Bucket).
-			Msg("R2 credentials found, initializing R2 client")
-		r2Client, err = NewR2Client(cfg)
-		if err != nil {
-			logger.Get().Error().
-				Err(err).
-				Msg("Failed to initialize R2 client")
-			return nil, fmt.Errorf("failed to initialize R2 client: %w", err)
+	searchIndex := search.NewIndex()
+	if cfg.SearchIndexPath != "" {
+		if searchIndex, err = search.OpenIndex(cfg.SearchIndexPath); err != nil {
+			if c, ok := store.(io.Closer); ok {
+				c.Close()
+			}
+			return nil, fmt.Errorf("failed to open search index: %w", err)
 		}
-		logger.Get().Info().Msg("R2 client initialized successfully")
-	} else {
+	}
+
+	// Index existing articles so new ones can join their story clusters,
+	// and bring the search index up to date with changes it missed
+	clusterer := cluster.NewClusterer(cfg.ClusterThreshold, cfg.ClusterWindow)
+	existing, err := store.List(context.Background(), storage.Query{})
+	if err != nil {
 		logger.Get().Warn().
-			Str("r2_endpoint", cfg.R2Endpoint).
-			Str("r2_access_key", func() string {
-				if len(cfg.R2AccessKey) > 4 {
-					return cfg.R2AccessKey[:4] + "***"
-				}
-				return cfg.R2AccessKey
-			}()).
-			Str("r2_secret_key", func() string {
-				if len(cfg.R2SecretKey) > 4 {
-					return cfg.R2SecretKey[:4] + "***"
-				}
-				return cfg.R2SecretKey
-			}()).
-			Str("r2_bucket", cfg.R2Bucket).
-			Msg("R2 credentials incomplete or missing")
+			Err(err).
+			Msg("Failed to load existing news for clustering and search")
+	} else {
+		// Store the clusters of items that had none, so their cluster
+		// URLs survive restarts
+		for _, item := range clusterer.Load(existing) {
+			if err := store.Update(context.Background(), item, ""); err != nil {
+				logger.Get().Warn().
+					Err(err).
+					Str("id", item.ID).
+					Msg("Failed to store cluster of existing news item")
+			}
+		}
+		if n, err := searchIndex.Sync(existing); err != nil {
+			logger.Get().Warn().
+				Err(err).
+				Msg("Failed to update search index")
+		} else {
+			logger.Get().Info().
+				Int("changed", n).
+				Int("indexed", searchIndex.Len()).
+				Msg("Search index updated")
+		}
 	}
 
+	callbackURL := strings.TrimRight(cfg.PublicURL, "/") + "/api/v1/websub/callback"
+
 	return &Handlers{
-		config:    cfg,
-		redis:     redis,
-		storage:   storage,
-		processor: feed.NewProcessor(redis),
-		gemini:    gemini,
-		postProc:  ai.NewPostProcessor(),
-		r2Client:  r2Client,
+		config:      cfg,
+		redis:       redis,
+		store:       store,
+		processor:   feed.NewProcessor(redis, cfg),
+		gemini:      gemini,
+		postProc:    ai.NewPostProcessor(cfg.Categories, cfg.UnmappedCategory),
+		clusterer:   clusterer,
+		searchIndex: searchIndex,
+		jobs:        newJobStore(),
+		subscriber:  ingest.NewSubscriber(callbackURL, cfg.Sources),
 	}, nil
 }
 
+// Close releases the resources held by the news store and the search index
+func (h *Handlers) Close() error {
+	err := h.searchIndex.Close()
+	if c, ok := h.store.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
 // HealthCheck handles the /health endpoint
 func (h *Handlers) HealthCheck(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
@@ -156,24 +134,38 @@ func (h *Handlers) HealthCheck(c *fiber.Ctx) error {
 	})
 }
 
-// GetNews handles GET /api/news
+// GetNews handles GET /api/news. Its query parameters are validated by
+// middleware.ValidateQueryParams into newsListParams. Pages are selected
+// with page or with the opaque next_cursor and prev_cursor tokens, which
+// stay stable while new items arrive. Only published items are listed.
 func (h *Handlers) GetNews(c *fiber.Ctx) error {
-	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	if page < 1 {
-		page = 1
+	params, ok := c.Locals("queryParams").(*newsListParams)
+	if !ok {
+		params = &newsListParams{}
 	}
+	params.Status = models.StatusPublished
+	return h.respondNews(c, params)
+}
 
-	pageSize, _ := strconv.Atoi(c.Query("page_size", "20"))
-	switch {
-	case pageSize > 100:
-		pageSize = 100
-	case pageSize <= 0:
-		pageSize = 20
+// ListNewsAdmin handles GET /api/admin/news. It takes the parameters of
+// GetNews and lists items in every editorial state unless status is given.
+func (h *Handlers) ListNewsAdmin(c *fiber.Ctx) error {
+	params, ok := c.Locals("queryParams").(*newsListParams)
+	if !ok {
+		params = &newsListParams{}
 	}
+	return h.respondNews(c, params)
+}
 
+// respondNews responds with the page of news selected by params
+func (h *Handlers) respondNews(c *fiber.Ctx, params *newsListParams) error {
 	// Get news from storage
-	news, err := h.storage.ListNews(c.Context(), page, pageSize)
+	page, err := h.listNews(c.Context(), params)
+	if errors.Is(err, errInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		logger.Get().Error().Err(err).Msg("Error getting news")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -181,16 +173,41 @@ func (h *Handlers) GetNews(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"page":       page,
-		"page_size":  pageSize,
-		"total":      len(news),
-		"items":      news,
-	})
+	if link := linkHeader(c, page); link != "" {
+		c.Set(fiber.HeaderLink, link)
+	}
+	response := fiber.Map{
+		"page_size": params.pageSize(),
+		"total":     page.total,
+		"items":     page.items,
+	}
+	if params.Cursor == "" {
+		response["page"] = params.page()
+	}
+	if page.next != "" {
+		response["next_cursor"] = page.next
+	}
+	if page.prev != "" {
+		response["prev_cursor"] = page.prev
+	}
+	return c.JSON(response)
 }
 
-// GetNewsByID handles GET /api/news/:id
+// GetNewsByID handles GET /api/news/:id. Items that are not published are
+// not found.
 func (h *Handlers) GetNewsByID(c *fiber.Ctx) error {
+	return h.respondNewsItem(c, true)
+}
+
+// GetNewsAdmin handles GET /api/admin/news/:id. It returns items in every
+// editorial state, with their ETag for edits and status changes.
+func (h *Handlers) GetNewsAdmin(c *fiber.Ctx) error {
+	return h.respondNewsItem(c, false)
+}
+
+// respondNewsItem responds with a news item and its related articles;
+// public responses leave out everything that is not published
+func (h *Handlers) respondNewsItem(c *fiber.Ctx, public bool) error {
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -198,33 +215,77 @@ func (h *Handlers) GetNewsByID(c *fiber.Ctx) error {
 		})
 	}
 
-	news, err := h.storage.GetNewsByID(c.Context(), id)
+	news, err := h.store.Get(c.Context(), id)
 	if err != nil {
 		logger.Get().Error().Err(err).Str("id", id).Msg("Error getting news item")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "News not found",
 		})
 	}
+	if public && !news.IsPublished() {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "News not found",
+		})
+	}
+	related := h.clusterer.Related(news.ID, relatedLimit)
+	if public {
+		related = publishedOnly(h.clusterer.Related(news.ID, 0))
+		related = related[:min(len(related), relatedLimit)]
+	}
 
-	return c.JSON(news)
+	// Editors send the ETag back in If-Match when changing the item
+	c.Set(fiber.HeaderETag, etag(news))
+	return c.JSON(struct {
+		*models.NewsItem
+		Related []models.RelatedNews `json:"related"`
+	}{
+		NewsItem: news,
+		Related:  related,
+	})
+}
+
+// GetCluster handles GET /api/clusters/:id with the published items of a cluster
+func (h *Handlers) GetCluster(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cluster ID is required",
+		})
+	}
+
+	timeline, ok := h.clusterer.Timeline(id)
+	timeline = publishedOnly(timeline)
+	if !ok || len(timeline) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Cluster not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"id":       id,
+		"total":    len(timeline),
+		"timeline": timeline,
+	})
+}
+
+// ListCategories handles GET /api/categories
+func (h *Handlers) ListCategories(c *fiber.Ctx) error {
+	categories := []config.Category{}
+	if h.config.Categories != nil {
+		categories = h.config.Categories.List
+	}
+	return c.JSON(fiber.Map{
+		"categories": categories,
+		"total":      len(categories),
+		"review":     config.CategoryReview,
+	})
 }
 
 // ProcessFeeds handles POST /api/admin/process
 func (h *Handlers) ProcessFeeds(c *fiber.Ctx) error {
-	// Check API key for admin endpoints
-	// Temporarily disabled for testing
-	// if h.config.AdminAPIKey != "" {
-	// 	apiKey := c.Get("X-API-Key")
-	// 	if apiKey != h.config.AdminAPIKey {
-	// 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-	// 			"error": "Invalid API key",
-	// 		})
-	// 	}
-	// }
-
 	log := logger.Get()
 	start := time.Now()
-	
+
 	log.Info().
 		Str("ip", c.IP()).
 		Str("method", c.Method()).
@@ -251,7 +312,9 @@ func (h *Handlers) ProcessFeeds(c *fiber.Ctx) error {
 		})
 	}
 
+	jobID := h.jobs.start(req.FeedURLs)
 	log.Info().
+		Str("job_id", jobID).
 		Int("feed_count", len(req.FeedURLs)).
 		Msg("Starting background processing of feeds")
 
@@ -260,112 +323,39 @@ func (h *Handlers) ProcessFeeds(c *fiber.Ctx) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 		defer cancel()
 
+		log := log.With().Str("job_id", jobID).Logger()
 		log.Info().
 			Int("feed_count", len(req.FeedURLs)).
 			Dur("timeout", 30*time.Minute).
 			Msg("Starting feed processing in background")
 
-		// Process feeds
-		items, err := h.processor.ProcessFeeds(ctx, req.FeedURLs)
+		// Process feeds; feeds that failed are recorded and the rest go on
+		items, results, err := h.processor.ProcessFeeds(ctx, req.FeedURLs)
+		h.jobs.update(jobID, func(job *models.Job) {
+			job.Feeds = results.Statuses()
+			job.Queued = len(items)
+		})
 		if err != nil {
 			log.Error().
 				Err(err).
 				Int("url_count", len(req.FeedURLs)).
 				Msg("Error processing feeds")
+			h.jobs.finish(jobID, err)
 			return
 		}
-
-		log.Info().
-			Int("items_to_process", len(items)).
-			Dur("fetch_duration", time.Since(start)).
-			Msg("Starting to process feed items with AI")
-
-		// Process each item with AI
-		for i, item := range items {
-			select {
-			case <-ctx.Done():
-				log.Warn().
-					Int("processed_items", i).
-					Int("total_items", len(items)).
-					Msg("Processing cancelled due to timeout")
-				return
-			default:
-				// Log progress every 5 items
-				if i > 0 && i%5 == 0 {
-					log.Info().
-						Int("processed", i).
-						Int("remaining", len(items)-i).
-						Dur("elapsed", time.Since(start)).
-						Msg("Processing feed items")
-				}
-
-				// Skip AI processing if Gemini client is not available
-				if h.gemini == nil {
-					log.Warn().
-						Str("title", item.TitleTR).
-						Int("item_index", i).
-						Msg("Gemini client not available, skipping AI processing")
-					continue
-				}
-
-				// Generate English version using Gemini
-				newsItem, err := h.gemini.GenerateEnglishNews(ctx, item)
-				if err != nil {
-					log.Error().
-						Err(err).
-						Str("title", item.TitleTR).
-						Int("item_index", i).
-						Msg("Error generating English news")
-					continue
-				}
-
-				// Post-process the generated content
-				if h.postProc != nil {
-					if err := h.postProc.ProcessNewsItem(newsItem); err != nil {
-						log.Error().
-							Err(err).
-							Str("id", newsItem.ID).
-							Msg("Error post-processing news item")
-						continue
-					}
-				}
-
-				// Save the processed item
-				if h.storage != nil {
-					if err := h.storage.SaveNews(ctx, newsItem); err != nil {
-						log.Error().
-							Err(err).
-							Str("id", newsItem.ID).
-							Msg("Error saving news item")
-					}
-				}
-
-				// Save to R2 if configured
-				if h.r2Client != nil {
-					if err := h.r2Client.SaveNewsToR2(ctx, newsItem); err != nil {
-						log.Error().
-							Err(err).
-							Str("id", newsItem.ID).
-							Msg("Error saving news item to R2")
-					} else {
-						log.Info().
-							Str("id", newsItem.ID).
-							Msg("Successfully saved news item to R2")
-					}
-				}
-
-				// Mark as processed
-				if h.processor != nil {
-					if err := h.processor.MarkAsProcessed(ctx, []string{item.Guid}, h.config.CacheTTL); err != nil {
-						log.Error().
-							Err(err).
-							Str("guid", item.Guid).
-							Msg("Error marking item as processed")
-					}
-				}
+		if failed := results.Failed(); len(failed) > 0 {
+			failedURLs := make([]string, len(failed))
+			for i, res := range failed {
+				failedURLs[i] = res.URL
 			}
+			log.Warn().
+				Strs("failed_feeds", failedURLs).
+				Int("feed_count", len(results)).
+				Msg("Some feeds failed, continuing with the rest")
 		}
 
+		err = h.generateItems(ctx, jobID, items)
+		h.jobs.finish(jobID, err)
 		log.Info().
 			Int("total_items_processed", len(items)).
 			Dur("total_duration", time.Since(start)).
@@ -380,21 +370,103 @@ func (h *Handlers) ProcessFeeds(c *fiber.Ctx) error {
 		"status":  "started",
 		"message": fmt.Sprintf("Processing %d feed(s) in the background", len(req.FeedURLs)),
 		"feeds":   len(req.FeedURLs),
+		"job_id":  jobID,
 	})
 }
 
-// DeleteNews handles DELETE /api/admin/news/:id
-func (h *Handlers) DeleteNews(c *fiber.Ctx) error {
-	// Check API key for admin endpoints
-	if h.config.AdminAPIKey != "" {
-		apiKey := c.Get("X-API-Key")
-		if apiKey != h.config.AdminAPIKey {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid API key",
-			})
+// countItem records the outcome of one item in the job record
+func (h *Handlers) countItem(jobID string, ok bool) {
+	h.jobs.update(jobID, func(job *models.Job) {
+		if ok {
+			job.Processed++
+		} else {
+			job.Failed++
+		}
+	})
+}
+
+// ListJobs handles GET /api/admin/jobs
+func (h *Handlers) ListJobs(c *fiber.Ctx) error {
+	jobs := h.jobs.list()
+	return c.JSON(fiber.Map{
+		"jobs":  jobs,
+		"total": len(jobs),
+	})
+}
+
+// GetJob handles GET /api/admin/jobs/:id
+func (h *Handlers) GetJob(c *fiber.Ctx) error {
+	job, ok := h.jobs.get(c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job not found",
+		})
+	}
+	return c.JSON(job)
+}
+
+// releaseItems gives up the dedup claims of items that were not processed,
+// so the next run picks them up again
+func (h *Handlers) releaseItems(items []models.FeedItem) {
+	if h.processor == nil || len(items) == 0 {
+		return
+	}
+
+	// The job context may already be cancelled, so release on a fresh one
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.processor.Release(ctx, items); err != nil {
+		logger.Get().Error().
+			Err(err).
+			Int("item_count", len(items)).
+			Msg("Error releasing item claims")
+	}
+}
+
+// FeedHealth handles GET /api/admin/feeds/health
+func (h *Handlers) FeedHealth(c *fiber.Ctx) error {
+	feeds := h.processor.Health().Snapshot()
+	disabled := 0
+	for _, f := range feeds {
+		if f.Disabled {
+			disabled++
 		}
 	}
 
+	return c.JSON(fiber.Map{
+		"feeds":      feeds,
+		"total":      len(feeds),
+		"disabled":   disabled,
+		"rejections": h.processor.Rejections(),
+	})
+}
+
+// EnableFeed handles POST /api/admin/feeds/enable
+func (h *Handlers) EnableFeed(c *fiber.Ctx) error {
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.URL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Feed URL is required",
+		})
+	}
+
+	if !h.processor.Health().Enable(req.URL) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Feed not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "enabled",
+		"url":    req.URL,
+	})
+}
+
+// DeleteNews handles DELETE /api/admin/news/:id
+func (h *Handlers) DeleteNews(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -402,13 +474,21 @@ func (h *Handlers) DeleteNews(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.storage.DeleteNews(c.Context(), id); err != nil {
-		logger.Get().Error().Err(err).Str("id", id).Msg("Error getting news item")
+	if err := h.store.Delete(c.Context(), id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "News not found",
+			})
+		}
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error deleting news item")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get news item",
+			"error": "Failed to delete news item",
 		})
 	}
 
+	h.clusterer.Remove(id)
+	h.searchIndex.Remove(id)
+
 	return c.JSON(fiber.Map{
 		"status":  "deleted",
 		"message": "News item deleted successfully",
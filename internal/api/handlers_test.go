@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/ai"
+	"github.com/bilgisen/goen/internal/cache"
+	"github.com/bilgisen/goen/internal/cluster"
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/feed"
+	"github.com/bilgisen/goen/internal/search"
+	"github.com/bilgisen/goen/internal/storage"
+)
+
+// newTestHandlers returns handlers on a file store in a temporary directory
+// and the mock cache, without a Gemini client
+func newTestHandlers(t *testing.T) *Handlers {
+	t.Helper()
+	sources, err := config.NewSources(config.Source{})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	categories, err := config.NewCategories(
+		config.Category{Slug: "economy", Name: "Economy", Labels: []string{"Ekonomi"}},
+		config.Category{Slug: "sports", Name: "Sports", Labels: []string{"Spor"}},
+	)
+	if err != nil {
+		t.Fatalf("Failed to init categories: %v", err)
+	}
+	cfg := &config.Config{
+		CacheTTL:         time.Hour,
+		ClaimTTL:         time.Minute,
+		Sources:          sources,
+		Categories:       categories,
+		UnmappedCategory: config.UnmappedReview,
+		ClusterThreshold: 0.35,
+		ClusterWindow:    72 * time.Hour,
+	}
+
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	redisClient, err := cache.NewMockRedisClient(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create mock cache: %v", err)
+	}
+
+	return &Handlers{
+		config:      cfg,
+		redis:       redisClient,
+		store:       store,
+		processor:   feed.NewProcessor(redisClient, cfg),
+		postProc:    ai.NewPostProcessor(categories, cfg.UnmappedCategory),
+		clusterer:   cluster.NewClusterer(cfg.ClusterThreshold, cfg.ClusterWindow),
+		searchIndex: search.NewIndex(),
+		jobs:        newJobStore(),
+	}
+}
+
+// fakeGemini serves the Gemini API, answering every prompt with the news
+// item generate returns, and points the handlers at it
+func fakeGemini(t *testing.T, h *Handlers, generate func(prompt string) map[string]interface{}) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Contents []struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"contents"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Contents) == 0 || len(req.Contents[0].Parts) == 0 {
+			http.Error(w, `{"error": {"message": "bad request"}}`, http.StatusBadRequest)
+			return
+		}
+		text, _ := json.Marshal(generate(req.Contents[0].Parts[0].Text))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"candidates": []interface{}{map[string]interface{}{
+				"content": map[string]interface{}{
+					"parts": []interface{}{map[string]interface{}{"text": string(text)}},
+				},
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	h.gemini = ai.NewGeminiClient("test", "gemini-test", h.config.Categories).SetBaseURL(server.URL)
+}
+
+// generatedNews returns a valid generated item in category
+func generatedNews(title, category string) map[string]interface{} {
+	return map[string]interface{}{
+		"seo_title":       title,
+		"seo_description": "A short description of " + title,
+		"tldr":            []string{"First point", "Second point"},
+		"content_md":      "## " + title + "\n\nThe generated article text is long enough to pass the checks.",
+		"category":        category,
+		"tags":            []string{"test"},
+	}
+}
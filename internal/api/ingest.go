@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bilgisen/goen/internal/feed"
+	"github.com/bilgisen/goen/internal/ingest"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Ingest handles POST /api/v1/ingest. Publishers push one or many items as
+// a FeedItem, a FeedItem array or a JSON Feed. X-Timestamp holds the Unix
+// time of the push and X-Signature ("sha256=<hex>") the HMAC of the
+// timestamp, a dot and the body with their secret.
+func (h *Handlers) Ingest(c *fiber.Ctx) error {
+	log := logger.Get()
+
+	publisher := c.Get("X-Publisher-ID")
+	src, ok := h.config.Sources.Publisher(publisher)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unknown publisher",
+		})
+	}
+
+	secret := src.Ingest.SecretValue()
+	if secret == "" {
+		log.Error().
+			Str("publisher", publisher).
+			Str("secret_env", src.Ingest.SecretEnv).
+			Msg("Publisher has no ingest secret configured")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Publisher is not configured for ingestion",
+		})
+	}
+
+	body := c.Body()
+	if err := ingest.VerifyPush(secret, body, c.Get("X-Signature"), c.Get("X-Timestamp"), time.Now()); err != nil {
+		log.Warn().
+			Err(err).
+			Str("publisher", publisher).
+			Str("ip", c.IP()).
+			Msg("Rejected ingest request")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid signature",
+		})
+	}
+
+	return h.acceptPush(c, src.URL, body)
+}
+
+// WebSubVerify handles GET /api/v1/websub/callback/:id, the hub's
+// subscription verification request
+func (h *Handlers) WebSubVerify(c *fiber.Ctx) error {
+	challenge, err := h.subscriber.Verify(
+		c.Params("id"),
+		c.Query("hub.mode"),
+		c.Query("hub.topic"),
+		c.Query("hub.challenge"),
+		c.Query("hub.lease_seconds"),
+		c.Query("hub.reason"),
+	)
+	if err != nil {
+		logger.Get().Warn().
+			Err(err).
+			Str("subscription", c.Params("id")).
+			Str("topic", c.Query("hub.topic")).
+			Msg("Refused WebSub verification")
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(challenge)
+}
+
+// WebSubDeliver handles POST /api/v1/websub/callback/:id, a content
+// distribution from the hub signed with X-Hub-Signature
+func (h *Handlers) WebSubDeliver(c *fiber.Ctx) error {
+	body := c.Body()
+	source, err := h.subscriber.Authenticate(c.Params("id"), body, c.Get("X-Hub-Signature"))
+	if errors.Is(err, ingest.ErrUnknownSubscription) {
+		// 410 tells the hub to drop the subscription
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		// Hubs must get a 2xx even for bad signatures; the content is dropped
+		logger.Get().Warn().
+			Err(err).
+			Str("subscription", c.Params("id")).
+			Msg("Ignored WebSub delivery with invalid signature")
+		return c.SendStatus(fiber.StatusAccepted)
+	}
+
+	return h.acceptPush(c, source, body)
+}
+
+// WebSubSubscriptions handles GET /api/admin/websub
+func (h *Handlers) WebSubSubscriptions(c *fiber.Ctx) error {
+	subs := h.subscriber.Subscriptions()
+	return c.JSON(fiber.Map{
+		"subscriptions": subs,
+		"total":         len(subs),
+	})
+}
+
+// RunWebSub subscribes to the configured WebSub hubs and renews the
+// subscriptions until ctx is cancelled
+func (h *Handlers) RunWebSub(ctx context.Context) {
+	if len(h.subscriber.Subscriptions()) == 0 {
+		return
+	}
+	if h.config.PublicURL == "" {
+		logger.Get().Warn().Msg("PUBLIC_URL is not set, WebSub subscriptions are disabled")
+		return
+	}
+	h.subscriber.Run(ctx)
+}
+
+// acceptPush parses a pushed body and starts a job for its items
+func (h *Handlers) acceptPush(c *fiber.Ctx, source string, body []byte) error {
+	decoded, _, err := feed.DecodeBody(body, c.Get(fiber.HeaderContentType))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body encoding: " + err.Error(),
+		})
+	}
+	items, err := feed.ParseFeed(decoded, source)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body: " + err.Error(),
+		})
+	}
+	if len(items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No items in body",
+		})
+	}
+
+	jobID := h.startItemsJob(source, items)
+	logger.Get().Info().
+		Str("job_id", jobID).
+		Str("source", source).
+		Int("items", len(items)).
+		Msg("Accepted pushed items")
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status": "accepted",
+		"job_id": jobID,
+		"items":  len(items),
+	})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/ingest"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIngest(t *testing.T) {
+	h := newTestHandlers(t)
+	sources, err := config.NewSources(config.Source{}, config.Source{
+		URL:    "https://publisher.example/feed",
+		Ingest: &config.IngestConfig{Publisher: "publisher", Secret: "s3cret"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	h.config.Sources = sources
+	fakeGemini(t, h, func(string) map[string]interface{} {
+		return generatedNews("Pushed news", "economy")
+	})
+	app := fiber.New()
+	app.Post("/ingest", h.Ingest)
+
+	body := `{"guid":"1","title":"Ekonomi haberi","content":"İçerik","url":"https://publisher.example/1"}`
+	now := time.Now()
+	push := func(publisher, signature string, ts time.Time) (int, map[string]interface{}) {
+		req := httptest.NewRequest("POST", "/ingest", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("X-Publisher-ID", publisher)
+		req.Header.Set("X-Timestamp", strconv.FormatInt(ts.Unix(), 10))
+		req.Header.Set("X-Signature", signature)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var out map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	valid := ingest.SignPush("s3cret", now, []byte(body))
+	if status, _ := push("stranger", valid, now); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown publisher, got %d", status)
+	}
+	if status, _ := push("publisher", ingest.SignPush("wrong", now, []byte(body)), now); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, got %d", status)
+	}
+	old := now.Add(-time.Hour)
+	if status, _ := push("publisher", ingest.SignPush("s3cret", old, []byte(body)), old); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected 401 for a replayed old push, got %d", status)
+	}
+	if jobs := h.jobs.list(); len(jobs) != 0 {
+		t.Fatalf("Expected rejected pushes not to start jobs, got %d", len(jobs))
+	}
+
+	status, out := push("publisher", valid, now)
+	if status != fiber.StatusAccepted {
+		t.Fatalf("Expected 202 for a signed push, got %d", status)
+	}
+	jobID, _ := out["job_id"].(string)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, ok := h.jobs.get(jobID)
+		if !ok {
+			t.Fatalf("Expected job %q to be recorded", jobID)
+		}
+		if job.Status != models.JobRunning {
+			if job.Status != models.JobCompleted || job.Processed != 1 {
+				t.Errorf("Expected the pushed item to be saved, got %+v", job)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job %s did not finish", jobID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// maxJobs is the number of job records kept in memory
+const maxJobs = 50
+
+// jobStore keeps the records of recent processing runs
+type jobStore struct {
+	mu    sync.RWMutex
+	jobs  map[string]*models.Job
+	order []string // oldest first
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{jobs: make(map[string]*models.Job)}
+}
+
+// start records a new running job and returns its ID
+func (s *jobStore) start(feedURLs []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := fmt.Sprintf("job-%d", time.Now().UnixNano())
+	s.jobs[id] = &models.Job{
+		ID:        id,
+		Status:    models.JobRunning,
+		FeedURLs:  feedURLs,
+		StartedAt: time.Now(),
+	}
+	s.order = append(s.order, id)
+
+	// Drop the oldest records beyond the limit
+	for len(s.order) > maxJobs {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+	return id
+}
+
+// update applies fn to a job under the store lock
+func (s *jobStore) update(id string, fn func(job *models.Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// finish marks a job as done. Jobs with a fatal error fail; jobs where some
+// feeds or items failed are partial.
+func (s *jobStore) finish(id string, err error) {
+	s.update(id, func(job *models.Job) {
+		job.FinishedAt = time.Now()
+		switch {
+		case err != nil:
+			job.Status = models.JobFailed
+			job.Error = err.Error()
+		case job.Failed > 0 || hasFailedFeed(job.Feeds):
+			job.Status = models.JobPartial
+		default:
+			job.Status = models.JobCompleted
+		}
+	})
+}
+
+// get returns a copy of a job
+func (s *jobStore) get(id string) (models.Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return models.Job{}, false
+	}
+	return copyJob(job), true
+}
+
+// list returns copies of all jobs, newest first
+func (s *jobStore) list() []models.Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]models.Job, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, copyJob(s.jobs[s.order[i]]))
+	}
+	return jobs
+}
+
+func copyJob(job *models.Job) models.Job {
+	c := *job
+	c.Feeds = append([]models.FeedStatus(nil), job.Feeds...)
+	return c
+}
+
+func hasFailedFeed(feeds []models.FeedStatus) bool {
+	for _, f := range feeds {
+		if f.Status == models.FeedStatusFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bilgisen/goen/internal/middleware"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errInvalidCursor is returned for cursors that cannot be decoded or were
+// taken in another sort order
+var errInvalidCursor = errors.New("invalid cursor for this sort order")
+
+// newsListParams are the query parameters of GET /api/v1/news. Dates are
+// RFC 3339 timestamps or plain dates; a plain date as upper bound includes
+// the whole day. Status is only honoured by the admin listing; the public
+// one shows published items.
+type newsListParams struct {
+	Page          int    `query:"page" validate:"omitempty,min=1"`
+	PageSize      int    `query:"page_size" validate:"omitempty,min=1,max=100"`
+	Category      string `query:"category" validate:"omitempty,max=64"`
+	Tag           string `query:"tag" validate:"omitempty,max=100"`
+	Source        string `query:"source" validate:"omitempty,max=100"`
+	Language      string `query:"language" validate:"omitempty,alpha,min=2,max=3"`
+	CreatedFrom   string `query:"created_from" validate:"omitempty,date"`
+	CreatedTo     string `query:"created_to" validate:"omitempty,date"`
+	PublishedFrom string `query:"published_from" validate:"omitempty,date"`
+	PublishedTo   string `query:"published_to" validate:"omitempty,date"`
+	HasImage      *bool  `query:"has_image"`
+	Status        string `query:"status" validate:"omitempty,oneof=draft in_review published rejected"`
+	Sort          string `query:"sort" validate:"omitempty,oneof=created_at published_at"`
+	Order         string `query:"order" validate:"omitempty,oneof=asc desc"`
+	// Cursor is a next_cursor or prev_cursor token; it replaces page
+	Cursor string `query:"cursor" validate:"omitempty,max=512"`
+}
+
+// pageSize returns the requested page size, 20 by default
+func (p *newsListParams) pageSize() int {
+	if p.PageSize == 0 {
+		return 20
+	}
+	return p.PageSize
+}
+
+// page returns the requested page, 1 by default
+func (p *newsListParams) page() int {
+	if p.Page == 0 {
+		return 1
+	}
+	return p.Page
+}
+
+// query converts validated parameters into a storage query for their page
+func (p *newsListParams) query() storage.Query {
+	return storage.Query{
+		Offset:        (p.page() - 1) * p.pageSize(),
+		Limit:         p.pageSize(),
+		Category:      strings.ToLower(p.Category),
+		Tag:           p.Tag,
+		Source:        p.Source,
+		Language:      p.Language,
+		CreatedFrom:   parseDate(p.CreatedFrom, false),
+		CreatedTo:     parseDate(p.CreatedTo, true),
+		PublishedFrom: parseDate(p.PublishedFrom, false),
+		PublishedTo:   parseDate(p.PublishedTo, true),
+		HasImage:      p.HasImage,
+		Status:        p.Status,
+		Sort:          p.Sort,
+		Ascending:     p.Order == "asc",
+	}
+}
+
+// newsPage is a page of news items with the cursors of its neighbours
+type newsPage struct {
+	items []*models.NewsItem
+	total int
+	next  string
+	prev  string
+}
+
+// listNews reads the page selected by params. One item beyond the page is
+// read to learn whether another page follows.
+func (h *Handlers) listNews(ctx context.Context, params *newsListParams) (*newsPage, error) {
+	q := params.query()
+	total, err := h.store.Count(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := false
+	if params.Cursor != "" {
+		cursor, err := storage.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		if !cursor.Matches(q) {
+			return nil, errInvalidCursor
+		}
+		backward = cursor.Backward
+		q.Offset = 0
+		q.After = &cursor
+		if backward {
+			// Walk towards the start of the list and restore the order below
+			q.Ascending = !q.Ascending
+		}
+	}
+
+	q.Limit++
+	items, err := h.store.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	more := len(items) == q.Limit
+	if more {
+		items = items[:len(items)-1]
+	}
+	if backward {
+		q.Ascending = !q.Ascending
+		slices.Reverse(items)
+	}
+
+	page := &newsPage{items: items, total: total}
+	if len(items) == 0 {
+		return page, nil
+	}
+	// Going forward, a previous page exists past the first page; going
+	// backward, the page we came from follows
+	hasNext, hasPrev := more, params.Cursor != "" || q.Offset > 0
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		page.next = storage.CursorAt(items[len(items)-1], q).Encode()
+	}
+	if hasPrev {
+		cursor := storage.CursorAt(items[0], q)
+		cursor.Backward = true
+		page.prev = cursor.Encode()
+	}
+	return page, nil
+}
+
+// linkHeader builds an RFC 8288 Link header for the next and previous
+// pages, keeping the other query parameters of the request
+func linkHeader(c *fiber.Ctx, page *newsPage) string {
+	var links []string
+	for _, l := range []struct{ rel, cursor string }{{"next", page.next}, {"prev", page.prev}} {
+		if l.cursor == "" {
+			continue
+		}
+		values, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+		values.Del("page")
+		values.Set("cursor", l.cursor)
+		links = append(links, fmt.Sprintf(`<%s%s?%s>; rel="%s"`, c.BaseURL(), c.Path(), values.Encode(), l.rel))
+	}
+	return strings.Join(links, ", ")
+}
+
+// parseDate parses a validated date parameter. Plain dates used as an
+// exclusive upper bound move to the next day so the day itself is included.
+func parseDate(value string, end bool) time.Time {
+	for _, layout := range middleware.DateLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		if end && layout == time.DateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/middleware"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+var linkPattern = regexp.MustCompile(`<([^>]+)>; rel="(\w+)"`)
+
+// getNewsPage requests a page of the news list and returns the IDs of its
+// items and the request URIs of its Link relations
+func getNewsPage(t *testing.T, app *fiber.App, uri string) (string, map[string]string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", uri, nil))
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", uri, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected 200 for %s, got %d", uri, resp.StatusCode)
+	}
+
+	var body struct {
+		Items []models.NewsItem `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response of %s: %v", uri, err)
+	}
+	ids := make([]string, len(body.Items))
+	for i, item := range body.Items {
+		ids[i] = item.ID
+	}
+
+	links := make(map[string]string)
+	for _, m := range linkPattern.FindAllStringSubmatch(resp.Header.Get(fiber.HeaderLink), -1) {
+		u, err := url.Parse(m[1])
+		if err != nil {
+			t.Fatalf("Invalid link %q: %v", m[1], err)
+		}
+		if u.Path != "/news" || u.Query().Get("page_size") != "2" || u.Query().Get("category") != "economy" {
+			t.Errorf("Expected the %s link to keep the path and parameters, got %s", m[2], m[1])
+		}
+		links[m[2]] = u.RequestURI()
+	}
+	return strings.Join(ids, ","), links
+}
+
+func TestGetNewsCursorPaging(t *testing.T) {
+	h := newTestHandlers(t)
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		item := &models.NewsItem{
+			ID:        fmt.Sprint(i),
+			Category:  "economy",
+			Status:    models.StatusPublished,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := h.store.Save(context.Background(), item); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	app := fiber.New()
+	app.Get("/news", middleware.ValidateQueryParams(&newsListParams{}), h.GetNews)
+
+	ids, links := getNewsPage(t, app, "/news?category=economy&page_size=2")
+	if ids != "5,4" || links["next"] == "" || links["prev"] != "" {
+		t.Fatalf("Expected the first page 5,4 with only a next link, got %s, %v", ids, links)
+	}
+
+	// Forward across the page boundaries to the last page
+	ids, links = getNewsPage(t, app, links["next"])
+	if ids != "3,2" || links["next"] == "" || links["prev"] == "" {
+		t.Fatalf("Expected the second page 3,2 with both links, got %s, %v", ids, links)
+	}
+	ids, links = getNewsPage(t, app, links["next"])
+	if ids != "1" || links["next"] != "" || links["prev"] == "" {
+		t.Fatalf("Expected the last page 1 with only a prev link, got %s, %v", ids, links)
+	}
+
+	// And back again, newest first on every page
+	ids, links = getNewsPage(t, app, links["prev"])
+	if ids != "3,2" || links["next"] == "" || links["prev"] == "" {
+		t.Fatalf("Expected to page back to 3,2 with both links, got %s, %v", ids, links)
+	}
+	ids, links = getNewsPage(t, app, links["prev"])
+	if ids != "5,4" || links["prev"] != "" {
+		t.Errorf("Expected to page back to 5,4 without a prev link, got %s, %v", ids, links)
+	}
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bilgisen/goen/internal/ai"
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/feed"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+)
+
+// generateItems turns claimed feed items into news items with AI, saves them
+// and records the outcome of each item in the job. Items that fail are
+// released so a later run picks them up again. It returns the context error
+// when the job runs out of time.
+func (h *Handlers) generateItems(ctx context.Context, jobID string, items []models.FeedItem) error {
+	log := logger.Get().With().Str("job_id", jobID).Logger()
+	start := time.Now()
+
+	log.Info().
+		Int("items_to_process", len(items)).
+		Msg("Starting to process feed items with AI")
+
+	// Process each item with AI
+	for i, item := range items {
+		select {
+		case <-ctx.Done():
+			log.Warn().
+				Int("processed_items", i).
+				Int("total_items", len(items)).
+				Msg("Processing cancelled due to timeout")
+			h.releaseItems(items[i:])
+			h.jobs.update(jobID, func(job *models.Job) {
+				job.Failed += len(items) - i
+			})
+			return ctx.Err()
+		default:
+			// Log progress every 5 items
+			if i > 0 && i%5 == 0 {
+				log.Info().
+					Int("processed", i).
+					Int("remaining", len(items)-i).
+					Dur("elapsed", time.Since(start)).
+					Msg("Processing feed items")
+			}
+
+			// Skip AI processing if Gemini client is not available
+			if h.gemini == nil {
+				log.Warn().
+					Str("title", item.TitleTR).
+					Int("item_index", i).
+					Msg("Gemini client not available, skipping AI processing")
+				h.releaseItems([]models.FeedItem{item})
+				h.countItem(jobID, false)
+				continue
+			}
+
+			// Generate English version using Gemini
+			newsItem, err := h.gemini.GenerateEnglishNews(ctx, item)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("title", item.TitleTR).
+					Strs("flags", item.Flags).
+					Int("item_index", i).
+					Msg("Error generating English news")
+				h.releaseItems([]models.FeedItem{item})
+				h.countItem(jobID, false)
+				continue
+			}
+
+			// Post-process the generated content
+			if h.postProc != nil {
+				err := h.postProc.ProcessNewsItem(newsItem)
+				if errors.Is(err, ai.ErrUnmappedCategory) {
+					// Generating the item again would be rejected again, so it
+					// is marked processed instead of released
+					log.Warn().
+						Err(err).
+						Str("guid", item.Guid).
+						Str("title", item.TitleTR).
+						Msg("Rejected news item with a category outside the taxonomy")
+					h.markProcessed(ctx, item)
+					h.jobs.update(jobID, func(job *models.Job) {
+						job.Rejected++
+					})
+					continue
+				}
+				if err != nil {
+					log.Error().
+						Err(err).
+						Str("id", newsItem.ID).
+						Msg("Error post-processing news item")
+					h.releaseItems([]models.FeedItem{item})
+					h.countItem(jobID, false)
+					continue
+				}
+			}
+
+			// Save the processed item
+			if h.store != nil {
+				if err := h.saveGenerated(ctx, item, newsItem); err != nil {
+					log.Error().
+						Err(err).
+						Str("id", newsItem.ID).
+						Msg("Error saving news item")
+					h.releaseItems([]models.FeedItem{item})
+					h.countItem(jobID, false)
+					continue
+				}
+			}
+
+			h.markProcessed(ctx, item)
+			h.countItem(jobID, true)
+			if len(item.Flags) > 0 {
+				log.Warn().
+					Str("id", newsItem.ID).
+					Str("guid", item.Guid).
+					Strs("flags", item.Flags).
+					Msg("Generated news item from a flagged source item")
+				h.jobs.update(jobID, func(job *models.Job) {
+					job.Flagged++
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// markProcessed completes the dedup claim of an item that is done with, so
+// later runs skip it
+func (h *Handlers) markProcessed(ctx context.Context, item models.FeedItem) {
+	if h.processor == nil {
+		return
+	}
+	if err := h.processor.MarkAsProcessed(ctx, []models.FeedItem{item}, h.config.CacheTTL); err != nil {
+		logger.Get().Error().
+			Err(err).
+			Str("guid", item.Guid).
+			Msg("Error marking item as processed")
+	}
+}
+
+// saveGenerated stores a generated item under the stable ID of its source
+// item. An article generated from the same source item before gets a new
+// revision instead of a duplicate and keeps its editorial state; a new one
+// is published or put in review as its source is configured.
+func (h *Handlers) saveGenerated(ctx context.Context, item models.FeedItem, newsItem *models.NewsItem) error {
+	key := feed.ItemKey(item, "")
+	if h.processor != nil {
+		key = h.processor.ItemKey(item)
+	}
+	newsItem.ID = feed.NewsID(key)
+
+	current, err := h.store.Get(ctx, newsItem.ID)
+	if err == nil {
+		if err := h.reviseGenerated(ctx, current, newsItem, models.EditorAI, "regenerated from the source item"); err != nil {
+			return err
+		}
+		h.saveSourceItem(ctx, newsItem.ID, item)
+		return nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	// Sources that require review hold the item back from the public API,
+	// and so do flagged source items and items in the review bucket
+	newsItem.Status, newsItem.StatusBy, newsItem.StatusAt = models.StatusInReview, models.EditorAI, time.Now()
+	if reason := heldReason(newsItem); reason != "" {
+		newsItem.StatusReason = reason
+	} else if h.config.Sources.Get(item.Source).Publishes() {
+		newsItem.Status = models.StatusPublished
+		newsItem.PublishedAt = newsItem.StatusAt
+	}
+
+	// Group the item with earlier coverage of the same story
+	h.clusterer.Assign(newsItem)
+
+	newsItem.Revision = 1
+	if err := h.store.Save(ctx, newsItem); err != nil {
+		h.clusterer.Remove(newsItem.ID)
+		return err
+	}
+
+	// Make it searchable
+	h.searchIndex.Add(newsItem)
+
+	if err := h.store.SaveRevision(ctx, models.NewRevision(newsItem, models.EditorAI, "generated")); err != nil {
+		logger.Get().Warn().Err(err).Str("id", newsItem.ID).Msg("Failed to record first revision")
+	}
+	h.saveSourceItem(ctx, newsItem.ID, item)
+	return nil
+}
+
+// heldReason returns why a generated item must be reviewed before it is
+// published, or an empty string
+func heldReason(item *models.NewsItem) string {
+	switch {
+	case len(item.Flags) > 0:
+		return "source item flagged: " + strings.Join(item.Flags, ", ")
+	case item.Category == config.CategoryReview && item.CategoryCandidate != "":
+		return fmt.Sprintf("category %q is not in the taxonomy", item.CategoryCandidate)
+	case item.Category == config.CategoryReview:
+		return "category needs review"
+	}
+	return ""
+}
+
+// reviseGenerated stores a regenerated item as the next revision of current
+// and takes it off the public API if it has to be reviewed, see heldReason
+func (h *Handlers) reviseGenerated(ctx context.Context, current, next *models.NewsItem, editor, note string) error {
+	if err := h.revise(ctx, current, next, editor, note); err != nil {
+		return err
+	}
+	if reason := heldReason(next); reason != "" && next.CurrentStatus() == models.StatusPublished {
+		return h.holdForReview(ctx, next, reason)
+	}
+	return nil
+}
+
+// holdForReview moves a regenerated item that was published back to review
+func (h *Handlers) holdForReview(ctx context.Context, item *models.NewsItem, reason string) error {
+	held := *item
+	now := time.Now()
+	held.Status, held.StatusReason, held.StatusBy, held.StatusAt = models.StatusInReview, reason, models.EditorAI, now
+	held.UpdatedAt = now
+	if err := h.store.Update(ctx, &held, storage.VersionOf(item)); err != nil {
+		return fmt.Errorf("failed to hold news item for review: %w", err)
+	}
+	*item = held
+	h.clusterer.Update(item)
+	h.searchIndex.Add(item)
+	return nil
+}
+
+// saveSourceItem keeps the feed item a news item was generated from, so
+// the item can be regenerated later. The news item stays saved if it fails.
+func (h *Handlers) saveSourceItem(ctx context.Context, id string, item models.FeedItem) {
+	if err := h.store.SaveSourceItem(ctx, id, &item); err != nil {
+		logger.Get().Warn().Err(err).Str("id", id).Msg("Failed to store source item")
+	}
+}
+
+// ProcessFeedFile runs the items of a feed file dropped into the feed source
+// directory through the same pipeline as fetched feeds, as its own job. It
+// fails when any item failed, so the watcher leaves the file in place and
+// retries it; items that were saved are skipped by dedup on the next run.
+func (h *Handlers) ProcessFeedFile(ctx context.Context, path string, items []models.FeedItem) error {
+	source := "file://" + filepath.Base(path)
+	jobID := h.jobs.start([]string{source})
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	if err := h.runItemsJob(ctx, jobID, source, items); err != nil {
+		return err
+	}
+	if job, ok := h.jobs.get(jobID); ok && job.Failed > 0 {
+		return fmt.Errorf("%d of %d items of %s failed", job.Failed, job.Queued, source)
+	}
+	return nil
+}
+
+// startItemsJob runs pushed items through the pipeline in the background
+// and returns the ID of their job
+func (h *Handlers) startItemsJob(source string, items []models.FeedItem) string {
+	jobID := h.jobs.start([]string{source})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		if err := h.runItemsJob(ctx, jobID, source, items); err != nil {
+			logger.Get().Error().
+				Err(err).
+				Str("job_id", jobID).
+				Str("source", source).
+				Msg("Error processing pushed items")
+		}
+	}()
+	return jobID
+}
+
+// runItemsJob runs items that did not come from the fetcher, such as files
+// and pushes, through the pipeline and records the outcome in job jobID
+func (h *Handlers) runItemsJob(ctx context.Context, jobID, source string, items []models.FeedItem) error {
+	start := time.Now()
+
+	unique, err := h.processor.ProcessItems(ctx, items)
+	h.jobs.update(jobID, func(job *models.Job) {
+		status := models.FeedStatus{
+			URL:        source,
+			Status:     models.FeedStatusOK,
+			Items:      len(items),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			status.Status = models.FeedStatusFailed
+			status.Error = err.Error()
+		}
+		job.Feeds = []models.FeedStatus{status}
+		job.Queued = len(unique)
+	})
+	if err != nil {
+		h.jobs.finish(jobID, err)
+		return fmt.Errorf("failed to process items of %s: %w", source, err)
+	}
+
+	err = h.generateItems(ctx, jobID, unique)
+	h.jobs.finish(jobID, err)
+	return err
+}
+
+// WatchFeedFiles processes feed files dropped into the feed source directory
+// until ctx is cancelled. It does nothing when the watch interval is zero.
+func (h *Handlers) WatchFeedFiles(ctx context.Context) {
+	if h.config.FeedWatchInterval <= 0 || h.config.FeedSourcePath == "" {
+		return
+	}
+	feed.NewWatcher(h.config.FeedSourcePath, h.config.FeedWatchInterval, h.ProcessFeedFile).Run(ctx)
+}
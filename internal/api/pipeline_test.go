@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bilgisen/goen/internal/ai"
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func TestProcessFeedFileFailsWhenItemsFail(t *testing.T) {
+	h := newTestHandlers(t)
+	ctx := context.Background()
+	items := []models.FeedItem{
+		{Guid: "1", TitleTR: "Başlık bir", ContentTR: "İçerik bir", Url: "https://example.com/1"},
+		{Guid: "2", TitleTR: "Başlık iki", ContentTR: "İçerik iki", Url: "https://example.com/2"},
+	}
+
+	// Without a model every item fails, so the file must not be archived
+	if err := h.ProcessFeedFile(ctx, "/feeds/gunluk.json", items); err == nil {
+		t.Fatal("Expected an error when items of the file failed")
+	}
+
+	jobs := h.jobs.list()
+	if len(jobs) != 1 || jobs[0].Failed != 2 || jobs[0].Status != models.JobPartial {
+		t.Fatalf("Expected one partial job with 2 failed items, got %+v", jobs)
+	}
+
+	// The failed items were released, so the retry gets them again
+	unique, err := h.processor.ProcessItems(ctx, items)
+	if err != nil || len(unique) != 2 {
+		t.Errorf("Expected both items to be retried, got %d items, err %v", len(unique), err)
+	}
+}
+
+func TestGenerateItemsRejectsUnmappedCategory(t *testing.T) {
+	h := newTestHandlers(t)
+	h.config.UnmappedCategory = config.UnmappedReject
+	h.postProc = ai.NewPostProcessor(h.config.Categories, config.UnmappedReject)
+	fakeGemini(t, h, func(string) map[string]interface{} {
+		return generatedNews("Magazine news", "Magazin")
+	})
+	ctx := context.Background()
+	items := []models.FeedItem{{Guid: "1", TitleTR: "Magazin haberi", ContentTR: "İçerik", Url: "https://example.com/1"}}
+
+	if err := h.ProcessFeedFile(ctx, "/feeds/magazin.json", items); err != nil {
+		t.Fatalf("Expected a rejected item not to fail the file, got %v", err)
+	}
+	jobs := h.jobs.list()
+	if len(jobs) != 1 || jobs[0].Rejected != 1 || jobs[0].Failed != 0 {
+		t.Fatalf("Expected one job with 1 rejected item, got %+v", jobs)
+	}
+
+	// The rejection is final, so later runs do not generate the item again
+	unique, err := h.processor.ProcessItems(ctx, items)
+	if err != nil || len(unique) != 0 {
+		t.Errorf("Expected the rejected item to be skipped, got %d items, err %v", len(unique), err)
+	}
+}
+
+func TestSaveGeneratedHoldsReviewBucket(t *testing.T) {
+	h := newTestHandlers(t)
+	ctx := context.Background()
+	item := models.FeedItem{Guid: "1", TitleTR: "Magazin haberi", ContentTR: "İçerik", Url: "https://example.com/1"}
+
+	news := &models.NewsItem{SeoTitle: "Magazine news", Category: config.CategoryReview, CategoryCandidate: "Magazin"}
+	if err := h.saveGenerated(ctx, item, news); err != nil {
+		t.Fatalf("saveGenerated failed: %v", err)
+	}
+	got, err := h.store.Get(ctx, news.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.StatusInReview || got.StatusReason == "" {
+		t.Errorf("Expected an item in the review bucket to be held for review, got %q, %q", got.Status, got.StatusReason)
+	}
+}
+
+func TestSaveGeneratedHoldsRegeneratedReviewBucket(t *testing.T) {
+	h := newTestHandlers(t)
+	ctx := context.Background()
+	item := models.FeedItem{Guid: "1", TitleTR: "Ekonomi haberi", ContentTR: "İçerik", Url: "https://example.com/1"}
+
+	if err := h.saveGenerated(ctx, item, &models.NewsItem{SeoTitle: "Economy news", Category: "economy"}); err != nil {
+		t.Fatalf("saveGenerated failed: %v", err)
+	}
+	news := &models.NewsItem{SeoTitle: "Magazine news", Category: config.CategoryReview, CategoryCandidate: "Magazin"}
+	if err := h.saveGenerated(ctx, item, news); err != nil {
+		t.Fatalf("saveGenerated of the regenerated item failed: %v", err)
+	}
+	got, err := h.store.Get(ctx, news.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.StatusInReview || got.Revision != 2 {
+		t.Errorf("Expected revision 2 to be taken off the public API, got %q at revision %d", got.Status, got.Revision)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bilgisen/goen/internal/ai"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// regenerateRequest is the optional body of RegenerateNews
+type regenerateRequest struct {
+	// Model replaces the configured model for this call
+	Model string `json:"model"`
+	// Prompt replaces the standard prompt, see ai.GenerateOptions
+	Prompt string `json:"prompt"`
+	// ExtraInstructions are appended to the prompt
+	ExtraInstructions string `json:"extra_instructions"`
+}
+
+// RegenerateNews handles POST /api/v1/admin/news/:id/regenerate. It runs
+// the stored source item of a news item through the model again, optionally
+// with another model, another prompt or instructions appended to the prompt,
+// and stores the result as a new revision. A published item whose result
+// needs review is taken off the public API. The response lists what changed
+// against the previous revision; both stay available for GET
+// .../revisions/diff.
+func (h *Handlers) RegenerateNews(c *fiber.Ctx) error {
+	var req regenerateRequest
+	if len(c.Body()) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(c.Body()))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body: " + err.Error(),
+			})
+		}
+	}
+	if req.Model != "" && !ai.ValidModel(req.Model) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Invalid model name %q", req.Model),
+		})
+	}
+	if req.Prompt != "" {
+		if _, err := ai.ParsePrompt(req.Prompt); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	if h.gemini == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "AI generation is not configured",
+		})
+	}
+
+	id := c.Params("id")
+	current, err := h.store.Get(c.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "News not found",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error getting news item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get news item",
+		})
+	}
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" && ifMatch != "*" && !matchesETag(ifMatch, etag(current)) {
+		c.Set(fiber.HeaderETag, etag(current))
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "News item was changed since it was read",
+		})
+	}
+
+	source, err := h.store.SourceItem(c.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "The source item of this news item was not stored, so it cannot be regenerated",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error getting source item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get source item",
+		})
+	}
+
+	opts := ai.GenerateOptions{Model: req.Model, Prompt: req.Prompt, ExtraInstructions: req.ExtraInstructions}
+	next, err := h.gemini.GenerateEnglishNewsWith(c.Context(), *source, opts)
+	if err == nil && h.postProc != nil {
+		err = h.postProc.ProcessNewsItem(next)
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error regenerating news item")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "Failed to regenerate news item: " + err.Error(),
+		})
+	}
+
+	note := fmt.Sprintf("regenerated with %s, prompt %s", next.Model, next.PromptVersion)
+	if instructions := strings.TrimSpace(req.ExtraInstructions); instructions != "" {
+		note += ": " + instructions
+	}
+	previous := *current
+	err = h.reviseGenerated(c.Context(), current, next, c.Get(editorHeader, defaultEditor), note)
+	if errors.Is(err, storage.ErrConflict) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "News item was changed concurrently, retry",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error saving regenerated news item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save regenerated news item",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, etag(next))
+	return c.JSON(fiber.Map{
+		"item": next,
+		"from": fiber.Map{
+			"revision":       current.Revision,
+			"model":          previous.Model,
+			"prompt_version": previous.PromptVersion,
+		},
+		"to": fiber.Map{
+			"revision":       next.Revision,
+			"model":          next.Model,
+			"prompt_version": next.PromptVersion,
+		},
+		"changes": diffRevisions(&previous, next),
+	})
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bilgisen/goen/internal/ai"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newRegenerateApp stores news item 1001 with its source item and serves
+// RegenerateNews
+func newRegenerateApp(t *testing.T, h *Handlers) *fiber.App {
+	t.Helper()
+	saveTestItem(t, h, "1001")
+	source := &models.FeedItem{Guid: "1", TitleTR: "Ekonomi haberi", ContentTR: "İçerik", Url: "https://example.com/1"}
+	if err := h.store.SaveSourceItem(context.Background(), "1001", source); err != nil {
+		t.Fatalf("SaveSourceItem failed: %v", err)
+	}
+	app := fiber.New()
+	app.Post("/news/:id/regenerate", h.RegenerateNews)
+	return app
+}
+
+// postRegenerate regenerates news item 1001 and returns the status code
+func postRegenerate(t *testing.T, app *fiber.App, body string) int {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/news/1001/regenerate", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestRegenerateNewsAppendsExtraInstructions(t *testing.T) {
+	h := newTestHandlers(t)
+	var prompt string
+	fakeGemini(t, h, func(p string) map[string]interface{} {
+		prompt = p
+		return generatedNews("Regenerated title", "economy")
+	})
+	app := newRegenerateApp(t, h)
+
+	if status := postRegenerate(t, app, `{"instructions": "Write a poem"}`); status != fiber.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown field, got %d", status)
+	}
+	if status := postRegenerate(t, app, `{"extra_instructions": "Keep the headline neutral"}`); status != fiber.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !strings.Contains(prompt, "Additional instructions:\nKeep the headline neutral") {
+		t.Errorf("Expected the instructions after the standard prompt, got %q", prompt)
+	}
+	got, err := h.store.Get(context.Background(), "1001")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.SeoTitle != "Regenerated title" || !strings.HasPrefix(got.PromptVersion, ai.PromptVersion+"+") {
+		t.Errorf("Expected the regenerated item with a derived prompt version, got %q, %q", got.SeoTitle, got.PromptVersion)
+	}
+}
+
+func TestRegenerateNewsReplacesPrompt(t *testing.T) {
+	h := newTestHandlers(t)
+	var prompt string
+	fakeGemini(t, h, func(p string) map[string]interface{} {
+		prompt = p
+		return generatedNews("Regenerated title", "economy")
+	})
+	app := newRegenerateApp(t, h)
+
+	for _, body := range []string{`{"prompt": "Title: {{.Title"}`, `{"prompt": "Title: {{.Headline}}"}`} {
+		if status := postRegenerate(t, app, body); status != fiber.StatusBadRequest {
+			t.Errorf("Expected 400 for the invalid template %s, got %d", body, status)
+		}
+	}
+	if prompt != "" {
+		t.Fatalf("Expected invalid templates not to reach the model, got %q", prompt)
+	}
+
+	body := `{"prompt": "Translate as JSON. Title: {{.Title}} Content: {{.Content}}", "extra_instructions": "Be brief"}`
+	if status := postRegenerate(t, app, body); status != fiber.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if prompt != "Translate as JSON. Title: Ekonomi haberi Content: İçerik\n\nAdditional instructions:\nBe brief" {
+		t.Errorf("Expected the template to replace the prompt, got %q", prompt)
+	}
+	got, err := h.store.Get(context.Background(), "1001")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !strings.HasPrefix(got.PromptVersion, "custom-") || !strings.Contains(got.PromptVersion, "+") {
+		t.Errorf("Expected a prompt version naming the custom prompt and instructions, got %q", got.PromptVersion)
+	}
+}
+
+func TestRegenerateNewsHoldsReviewBucket(t *testing.T) {
+	h := newTestHandlers(t)
+	fakeGemini(t, h, func(string) map[string]interface{} {
+		return generatedNews("Regenerated title", "Magazin")
+	})
+	app := newRegenerateApp(t, h)
+
+	if status := postRegenerate(t, app, ""); status != fiber.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	got, err := h.store.Get(context.Background(), "1001")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.StatusInReview || got.StatusReason == "" || got.Revision != 2 {
+		t.Errorf("Expected revision 2 to be taken off the public API, got %q, %q at revision %d", got.Status, got.StatusReason, got.Revision)
+	}
+}
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/bilgisen/goen/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// editorHeader names the editor of admin changes; it defaults to defaultEditor
+const (
+	editorHeader  = "X-Editor"
+	defaultEditor = "admin"
+)
+
+// revisionField is a field of a news item compared between revisions.
+// Multi-line fields are compared line by line.
+type revisionField struct {
+	name  string
+	value func(*models.NewsItem) string
+	lines bool
+}
+
+var revisionFields = []revisionField{
+	{name: "seo_title", value: func(n *models.NewsItem) string { return n.SeoTitle }},
+	{name: "seo_description", value: func(n *models.NewsItem) string { return n.SeoDesc }},
+	{name: "tldr", value: func(n *models.NewsItem) string { return strings.Join(n.TLDR, "\n") }, lines: true},
+	{name: "content_md", value: func(n *models.NewsItem) string { return n.ContentMD }, lines: true},
+	{name: "category", value: func(n *models.NewsItem) string { return n.Category }},
+	{name: "tags", value: func(n *models.NewsItem) string { return strings.Join(n.Tags, ", ") }},
+	{name: "image", value: func(n *models.NewsItem) string { return n.Image }},
+	{name: "image_title", value: func(n *models.NewsItem) string { return n.ImageTitle }},
+	{name: "image_desc", value: func(n *models.NewsItem) string { return n.ImageDesc }},
+}
+
+// fieldChange is a changed field between two revisions: the old and new
+// values, or a line diff for multi-line fields
+type fieldChange struct {
+	Field string   `json:"field"`
+	From  string   `json:"from,omitempty"`
+	To    string   `json:"to,omitempty"`
+	Diff  []string `json:"diff,omitempty"`
+}
+
+// diffRevisions lists the fields that differ between two versions of an item
+func diffRevisions(from, to *models.NewsItem) []fieldChange {
+	changes := []fieldChange{}
+	for _, f := range revisionFields {
+		a, b := f.value(from), f.value(to)
+		if a == b {
+			continue
+		}
+		if f.lines {
+			changes = append(changes, fieldChange{Field: f.name, Diff: utils.LineDiff(a, b)})
+		} else {
+			changes = append(changes, fieldChange{Field: f.name, From: a, To: b})
+		}
+	}
+	return changes
+}
+
+// revise replaces the stored item current with next and records next as a
+// new revision. next keeps the identity, creation, cluster and editorial
+// state of current. Items stored before revisions existed get their current
+// version recorded first, so it can be rolled back to. It returns
+// storage.ErrConflict when current was changed since it was read.
+func (h *Handlers) revise(ctx context.Context, current, next *models.NewsItem, editor, note string) error {
+	version := storage.VersionOf(current)
+	if current.Revision == 0 {
+		current.Revision = 1
+		baseline := models.NewRevision(current, "", "recorded before the first edit")
+		if err := h.store.SaveRevision(ctx, baseline); err != nil && !errors.Is(err, storage.ErrRevisionExists) {
+			return fmt.Errorf("failed to record revision: %w", err)
+		}
+	}
+
+	next.ID = current.ID
+	next.CreatedAt = current.CreatedAt
+	next.PublishedAt = current.PublishedAt
+	next.ClusterID = current.ClusterID
+	next.FilePath = current.FilePath
+	next.Status = current.Status
+	next.StatusReason = current.StatusReason
+	next.StatusBy = current.StatusBy
+	next.StatusAt = current.StatusAt
+	next.UpdatedAt = time.Now()
+	next.Revision = current.Revision + 1
+
+	// The item is written first and only from the version that was read,
+	// so concurrent edits fail with ErrConflict and a failed write leaves
+	// no revision behind that would block the next attempt
+	if err := h.store.Update(ctx, next, version); err != nil {
+		return err
+	}
+	if err := h.store.SaveRevision(ctx, models.NewRevision(next, editor, note)); err != nil {
+		logger.Get().Warn().Err(err).Str("id", next.ID).Int("revision", next.Revision).Msg("Failed to record revision")
+	}
+	h.clusterer.Update(next)
+	h.searchIndex.Add(next)
+	return nil
+}
+
+// revisionNumber parses a revision number parameter; zero means unset
+func revisionNumber(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	var n int
+	if _, err := fmt.Sscan(value, &n); err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid revision %q", value)
+	}
+	return n, nil
+}
+
+// findRevision returns revision n of revs
+func findRevision(revs []*models.Revision, n int) (*models.Revision, bool) {
+	for _, rev := range revs {
+		if rev.Number == n {
+			return rev, true
+		}
+	}
+	return nil, false
+}
+
+// loadRevisions returns a news item and its revisions. For public
+// requests, items that are not published are not found.
+func (h *Handlers) loadRevisions(ctx context.Context, id string, public bool) (*models.NewsItem, []*models.Revision, error) {
+	item, err := h.store.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if public && !item.IsPublished() {
+		return nil, nil, fmt.Errorf("%w: %s is %s", storage.ErrNotFound, id, item.CurrentStatus())
+	}
+	revs, err := h.store.Revisions(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	return item, revs, nil
+}
+
+// revisionsError responds to a failure of loadRevisions
+func revisionsError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "News not found",
+		})
+	}
+	logger.Get().Error().Err(err).Str("id", c.Params("id")).Msg("Error getting revisions")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to get revisions",
+	})
+}
+
+// GetRevisions handles GET /api/v1/news/:id/revisions, oldest first
+func (h *Handlers) GetRevisions(c *fiber.Ctx) error {
+	return h.respondRevisions(c, true)
+}
+
+// GetRevisionsAdmin handles GET /api/v1/admin/news/:id/revisions for items
+// in every editorial state
+func (h *Handlers) GetRevisionsAdmin(c *fiber.Ctx) error {
+	return h.respondRevisions(c, false)
+}
+
+func (h *Handlers) respondRevisions(c *fiber.Ctx, public bool) error {
+	item, revs, err := h.loadRevisions(c.Context(), c.Params("id"), public)
+	if err != nil {
+		return revisionsError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"id":        item.ID,
+		"revision":  item.Revision,
+		"revisions": revs,
+	})
+}
+
+// DiffRevisions handles GET /api/v1/news/:id/revisions/diff?from=&to=. By
+// default it compares the latest revision with the one before it.
+func (h *Handlers) DiffRevisions(c *fiber.Ctx) error {
+	return h.respondDiff(c, true)
+}
+
+// DiffRevisionsAdmin handles GET /api/v1/admin/news/:id/revisions/diff for
+// items in every editorial state
+func (h *Handlers) DiffRevisionsAdmin(c *fiber.Ctx) error {
+	return h.respondDiff(c, false)
+}
+
+func (h *Handlers) respondDiff(c *fiber.Ctx, public bool) error {
+	from, err := revisionNumber(c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	to, err := revisionNumber(c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	item, revs, err := h.loadRevisions(c.Context(), c.Params("id"), public)
+	if err != nil {
+		return revisionsError(c, err)
+	}
+	if to == 0 && len(revs) > 0 {
+		to = revs[len(revs)-1].Number
+	}
+	if from == 0 {
+		from = to - 1
+	}
+
+	a, okA := findRevision(revs, from)
+	b, okB := findRevision(revs, to)
+	if !okA || !okB {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": fmt.Sprintf("Revisions %d and %d are not both stored", from, to),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"id":      item.ID,
+		"from":    a.Number,
+		"to":      b.Number,
+		"changes": diffRevisions(a.Content, b.Content),
+	})
+}
+
+// RollbackNews handles POST /api/v1/admin/news/:id/rollback. It restores
+// the content of an earlier revision as a new revision.
+func (h *Handlers) RollbackNews(c *fiber.Ctx) error {
+	var req struct {
+		Revision int `json:"revision"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Revision < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Revision number is required",
+		})
+	}
+
+	item, revs, err := h.loadRevisions(c.Context(), c.Params("id"), false)
+	if err != nil {
+		return revisionsError(c, err)
+	}
+	target, ok := findRevision(revs, req.Revision)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": fmt.Sprintf("Revision %d not found", req.Revision),
+		})
+	}
+	if target.Number == item.Revision {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("News item is already at revision %d", target.Number),
+		})
+	}
+
+	restored := *target.Content
+	editor := c.Get(editorHeader, defaultEditor)
+	err = h.revise(c.Context(), item, &restored, editor, fmt.Sprintf("rollback to revision %d", target.Number))
+	if errors.Is(err, storage.ErrConflict) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "News item was changed concurrently, retry",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", item.ID).Msg("Error rolling back news item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to roll back news item",
+		})
+	}
+	return c.JSON(&restored)
+}
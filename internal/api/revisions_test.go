@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+)
+
+// failingUpdates fails every Update while fail is set
+type failingUpdates struct {
+	storage.Store
+	fail bool
+}
+
+func (s *failingUpdates) Update(ctx context.Context, item *models.NewsItem, version string) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return s.Store.Update(ctx, item, version)
+}
+
+// saveTestItem stores a news item at revision 1
+func saveTestItem(t *testing.T, h *Handlers, id string) *models.NewsItem {
+	t.Helper()
+	ctx := context.Background()
+	item := &models.NewsItem{
+		ID:        id,
+		SeoTitle:  "Original title",
+		SeoDesc:   "The original description",
+		TLDR:      []string{"First point"},
+		ContentMD: "## Original title\n\nThe original article text is long enough to pass the checks.",
+		Category:  "economy",
+		Tags:      []string{"markets"},
+		Status:    models.StatusPublished,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Revision:  1,
+	}
+	if err := h.store.Save(ctx, item); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := h.store.SaveRevision(ctx, models.NewRevision(item, models.EditorAI, "generated")); err != nil {
+		t.Fatalf("SaveRevision failed: %v", err)
+	}
+	stored, err := h.store.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	return stored
+}
+
+func TestReviseLeavesNoRevisionWhenUpdateFails(t *testing.T) {
+	h := newTestHandlers(t)
+	store := &failingUpdates{Store: h.store, fail: true}
+	h.store = store
+	ctx := context.Background()
+	current := saveTestItem(t, h, "1001")
+
+	if err := h.revise(ctx, current, &models.NewsItem{SeoTitle: "New title"}, models.EditorAI, "regenerated"); err == nil {
+		t.Fatal("Expected revise to fail when the item cannot be written")
+	}
+	revs, err := h.store.Revisions(ctx, "1001")
+	if err != nil || len(revs) != 1 {
+		t.Fatalf("Expected only the first revision after the failed write, got %d, err %v", len(revs), err)
+	}
+
+	// The next attempt is not blocked by the failed one
+	store.fail = false
+	current, _ = h.store.Get(ctx, "1001")
+	if err := h.revise(ctx, current, &models.NewsItem{SeoTitle: "New title"}, models.EditorAI, "regenerated"); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	got, _ := h.store.Get(ctx, "1001")
+	if got == nil || got.Revision != 2 || got.SeoTitle != "New title" {
+		t.Errorf("Expected revision 2 with the new title, got %+v", got)
+	}
+	revs, _ = h.store.Revisions(ctx, "1001")
+	if len(revs) != 2 || revs[1].Content.SeoTitle != "New title" {
+		t.Errorf("Expected the second revision to hold the new title, got %d revisions", len(revs))
+	}
+}
+
+func TestReviseFailsOnStaleItem(t *testing.T) {
+	h := newTestHandlers(t)
+	ctx := context.Background()
+	stale := saveTestItem(t, h, "1001")
+
+	first := *stale
+	if err := h.revise(ctx, &first, &models.NewsItem{SeoTitle: "First edit"}, "editor", ""); err != nil {
+		t.Fatalf("First revise failed: %v", err)
+	}
+	second := *stale
+	err := h.revise(ctx, &second, &models.NewsItem{SeoTitle: "Second edit"}, "editor", "")
+	if !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("Expected ErrConflict when revising a stale item, got %v", err)
+	}
+	if got, _ := h.store.Get(ctx, "1001"); got == nil || got.SeoTitle != "First edit" {
+		t.Errorf("Expected the first edit to be kept, got %+v", got)
+	}
+}
@@ -1,18 +1,21 @@
 package api
 
 import (
+	"crypto/subtle"
 	"log"
 
 	"github.com/bilgisen/goen/internal/cache"
 	"github.com/bilgisen/goen/internal/config"
 	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/middleware"
+	"github.com/gofiber/fiber/v2"
 	fiberLogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/gofiber/fiber/v2"
 )
 
-// SetupRoutes configures all the routes for the application
-func SetupRoutes(app *fiber.App, redisClient cache.RedisInterface, cfg *config.Config) {
+// SetupRoutes configures all the routes for the application and returns
+// the handlers so background workers can share them
+func SetupRoutes(app *fiber.App, redisClient cache.RedisInterface, cfg *config.Config) *Handlers {
 	logger.Get().Info().
 		Str("r2_endpoint", cfg.R2Endpoint).
 		Str("r2_bucket", cfg.R2Bucket).
@@ -40,15 +43,47 @@ func SetupRoutes(app *fiber.App, redisClient cache.RedisInterface, cfg *config.C
 	// News endpoints
 	news := api.Group("/news")
 	{
-		news.Get("", handlers.GetNews)           // List news with pagination
-		news.Get("/:id", handlers.GetNewsByID)    // Get single news by ID
+		news.Get("", middleware.ValidateQueryParams(&newsListParams{}), handlers.GetNews) // List news with filters and pagination
+		news.Get("/:id", handlers.GetNewsByID)                                            // Get single news by ID
+		news.Get("/:id/revisions", handlers.GetRevisions)                                 // Revision history
+		news.Get("/:id/revisions/diff", handlers.DiffRevisions)                           // Changes between two revisions
 	}
 
-	// Admin endpoints (protected in production)
-	admin := api.Group("/admin")
+	// Push ingestion from publishers and WebSub hubs
+	api.Post("/ingest", handlers.Ingest)
+	api.Get("/websub/callback/:id", handlers.WebSubVerify)
+	api.Post("/websub/callback/:id", handlers.WebSubDeliver)
+
+	// Full-text search
+	api.Get("/search", handlers.Search)
+
+	// Category taxonomy
+	api.Get("/categories", handlers.ListCategories)
+
+	// Story cluster endpoints
+	api.Get("/clusters/:id", handlers.GetCluster) // Timeline of a story cluster
+
+	// Admin endpoints require the admin API key
+	admin := api.Group("/admin", adminAuth(cfg))
 	{
-		admin.Post("/process", handlers.ProcessFeeds) // Process new feeds
-		admin.Delete("/news/:id", handlers.DeleteNews) // Delete a news item
+		admin.Post("/process", handlers.ProcessFeeds)                                                 // Process new feeds
+		admin.Get("/jobs", handlers.ListJobs)                                                         // Recent processing jobs
+		admin.Get("/jobs/:id", handlers.GetJob)                                                       // Per-feed results of a job
+		admin.Get("/news", middleware.ValidateQueryParams(&newsListParams{}), handlers.ListNewsAdmin) // News in every editorial state
+		admin.Get("/news/:id", handlers.GetNewsAdmin)                                                 // News item in any state, with its ETag
+		admin.Get("/news/:id/revisions", handlers.GetRevisionsAdmin)                                  // Revision history in any state
+		admin.Get("/news/:id/revisions/diff", handlers.DiffRevisionsAdmin)                            // Changes between two revisions in any state
+		admin.Patch("/news/:id", handlers.PatchNews)                                                  // Change fields of a news item
+		admin.Put("/news/:id", handlers.ReplaceNews)                                                  // Replace the editable fields of a news item
+		admin.Delete("/news/:id", handlers.DeleteNews)                                                // Delete a news item
+		admin.Post("/news/:id/rollback", handlers.RollbackNews)                                       // Restore an earlier revision
+		admin.Post("/news/:id/regenerate", handlers.RegenerateNews)                                   // Run the source item through the model again
+		admin.Post("/news/:id/approve", handlers.ApproveNews)                                         // Publish a news item
+		admin.Post("/news/:id/reject", handlers.RejectNews)                                           // Reject a news item with a reason
+		admin.Post("/news/:id/status", handlers.SetNewsStatus)                                        // Move a news item to another editorial state
+		admin.Get("/feeds/health", handlers.FeedHealth)                                               // Per-feed fetch health
+		admin.Post("/feeds/enable", handlers.EnableFeed)                                              // Re-enable a disabled feed
+		admin.Get("/websub", handlers.WebSubSubscriptions)                                            // WebSub subscription states
 	}
 
 	// 404 Handler
@@ -74,4 +109,24 @@ func SetupRoutes(app *fiber.App, redisClient cache.RedisInterface, cfg *config.C
 		}
 		return nil
 	})
+
+	return handlers
+}
+
+// adminAuth checks the admin API key. Without a configured key the admin
+// endpoints are disabled rather than open.
+func adminAuth(cfg *config.Config) fiber.Handler {
+	if cfg.AdminAPIKey == "" {
+		logger.Get().Warn().Msg("ADMIN_API_KEY is not set, admin endpoints are disabled")
+		return func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Admin endpoints are disabled, set ADMIN_API_KEY to enable them",
+			})
+		}
+	}
+	return middleware.NewAuth(middleware.AuthConfig{
+		Validator: func(key string) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(key), []byte(cfg.AdminAPIKey)) == 1, nil
+		},
+	})
 }
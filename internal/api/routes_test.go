@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminAuth(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		key    string
+		header string
+		want   int
+	}{
+		{"no key configured", "", "", fiber.StatusServiceUnavailable},
+		{"no key configured, key sent", "", "anything", fiber.StatusServiceUnavailable},
+		{"missing key", "secret", "", fiber.StatusUnauthorized},
+		{"wrong key", "secret", "guess", fiber.StatusUnauthorized},
+		{"right key", "secret", "secret", fiber.StatusOK},
+	} {
+		app := fiber.New()
+		admin := app.Group("/admin", adminAuth(&config.Config{AdminAPIKey: tc.key}))
+		admin.Get("/jobs", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+		req := httptest.NewRequest("GET", "/admin/jobs", nil)
+		if tc.header != "" {
+			req.Header.Set("X-API-Key", tc.header)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tc.name, err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.want, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/search"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// searchResult is a news item found by a search, with its score and highlights
+type searchResult struct {
+	*models.NewsItem
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// Search handles GET /api/v1/search?q=. The query supports words, "quoted
+// phrases", field prefixes (title:, description:, tldr:, content:, tags:),
+// exclusions (-word) and the category: and source: filters. Only published
+// items are found.
+func (h *Handlers) Search(c *fiber.Ctx) error {
+	query, err := search.ParseQuery(c.Query("q"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	query.Status = models.StatusPublished
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.Query("page_size", "20"))
+	switch {
+	case pageSize > 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 20
+	}
+
+	results, err := h.searchIndex.Search(query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		logger.Get().Error().Err(err).Str("query", c.Query("q")).Msg("Error searching news")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search news",
+		})
+	}
+	items := make([]searchResult, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		item, err := h.store.Get(c.Context(), hit.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			// Deleted outside the API; forget it
+			h.searchIndex.Remove(hit.ID)
+			continue
+		}
+		if err != nil {
+			logger.Get().Error().Err(err).Str("id", hit.ID).Msg("Error getting search result")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get search results",
+			})
+		}
+		items = append(items, searchResult{NewsItem: item, Score: hit.Score, Highlights: hit.Highlights})
+	}
+
+	return c.JSON(fiber.Map{
+		"query":     c.Query("q"),
+		"page":      page,
+		"page_size": pageSize,
+		"total":     results.Total,
+		"items":     items,
+	})
+}
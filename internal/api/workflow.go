@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// statusChange is the body of the workflow endpoints. Status is only read
+// by SetNewsStatus; approve and reject imply it.
+type statusChange struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// ApproveNews handles POST /api/v1/admin/news/:id/approve. It publishes an
+// item and sets its publication date if it was never published.
+func (h *Handlers) ApproveNews(c *fiber.Ctx) error {
+	return h.changeStatus(c, models.StatusPublished)
+}
+
+// RejectNews handles POST /api/v1/admin/news/:id/reject. A reason is required.
+func (h *Handlers) RejectNews(c *fiber.Ctx) error {
+	return h.changeStatus(c, models.StatusRejected)
+}
+
+// SetNewsStatus handles POST /api/v1/admin/news/:id/status for the other
+// transitions, e.g. sending a rejected item back to draft
+func (h *Handlers) SetNewsStatus(c *fiber.Ctx) error {
+	return h.changeStatus(c, "")
+}
+
+// changeStatus moves an item to the status, or to the one in the body when
+// status is empty. If-Match is optional; when sent it must match. Either
+// way the item is only written if it is still the version that was read.
+func (h *Handlers) changeStatus(c *fiber.Ctx, status string) error {
+	var req statusChange
+	if len(c.Body()) > 0 {
+		if err := json.Unmarshal(c.Body(), &req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body: " + err.Error(),
+			})
+		}
+	}
+	if status == "" {
+		status = req.Status
+	}
+	if !models.ValidStatus(status) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Unknown status %q", status),
+		})
+	}
+	if status == models.StatusRejected && req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A reason is required to reject a news item",
+		})
+	}
+
+	id := c.Params("id")
+	current, err := h.store.Get(c.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "News not found",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error getting news item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get news item",
+		})
+	}
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" && ifMatch != "*" && !matchesETag(ifMatch, etag(current)) {
+		c.Set(fiber.HeaderETag, etag(current))
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "News item was changed since it was read",
+		})
+	}
+
+	from := current.CurrentStatus()
+	if from == status {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("News item is already %s", status),
+		})
+	}
+	if !models.CanTransition(from, status) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   fmt.Sprintf("Cannot move news item from %s to %s", from, status),
+			"status":  from,
+			"allowed": models.Transitions(from),
+		})
+	}
+
+	next := *current
+	now := time.Now()
+	next.Status = status
+	next.StatusReason = req.Reason
+	next.StatusBy = c.Get(editorHeader, defaultEditor)
+	next.StatusAt = now
+	next.UpdatedAt = now
+	if status == models.StatusPublished && next.PublishedAt.IsZero() {
+		next.PublishedAt = now
+	}
+
+	err = h.store.Update(c.Context(), &next, storage.VersionOf(current))
+	if errors.Is(err, storage.ErrConflict) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "News item was changed concurrently, retry",
+		})
+	}
+	if err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Error changing news status")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to change news status",
+		})
+	}
+	h.clusterer.Update(&next)
+	h.searchIndex.Add(&next)
+
+	logger.Get().Info().
+		Str("id", id).
+		Str("from", from).
+		Str("to", status).
+		Str("editor", next.StatusBy).
+		Msg("News status changed")
+
+	c.Set(fiber.HeaderETag, etag(&next))
+	return c.JSON(&next)
+}
+
+// publishedOnly drops the summaries of items the public API does not show
+func publishedOnly(related []models.RelatedNews) []models.RelatedNews {
+	out := make([]models.RelatedNews, 0, len(related))
+	for _, r := range related {
+		if r.Status == models.StatusPublished {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// racingStore runs race once, right after the first Get, to change the item
+// between a handler's read and write
+type racingStore struct {
+	storage.Store
+	race func()
+}
+
+func (s *racingStore) Get(ctx context.Context, id string) (*models.NewsItem, error) {
+	item, err := s.Store.Get(ctx, id)
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+	return item, err
+}
+
+func TestChangeStatusFailsOnConcurrentChange(t *testing.T) {
+	h := newTestHandlers(t)
+	saveTestItem(t, h, "1001")
+	store := h.store
+	h.store = &racingStore{Store: store, race: func() {
+		// An editor rejects the item while the approval is in flight
+		item, _ := store.Get(context.Background(), "1001")
+		item.Status, item.StatusReason, item.UpdatedAt = models.StatusRejected, "duplicate", time.Now()
+		if err := store.Update(context.Background(), item, ""); err != nil {
+			t.Errorf("Concurrent update failed: %v", err)
+		}
+	}}
+	app := fiber.New()
+	app.Post("/news/:id/status", h.SetNewsStatus)
+
+	req := httptest.NewRequest("POST", "/news/1001/status", strings.NewReader(`{"status": "in_review"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("Expected 409 for a concurrent change, got %d", resp.StatusCode)
+	}
+	if got, _ := store.Get(context.Background(), "1001"); got == nil || got.Status != models.StatusRejected {
+		t.Errorf("Expected the concurrent rejection to be kept, got %+v", got)
+	}
+}
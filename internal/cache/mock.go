@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/bilgisen/goen/internal/config"
@@ -9,13 +10,23 @@ import (
 
 // MockRedisClient provides a mock implementation for testing when Redis is not available
 type MockRedisClient struct {
-	data   map[string]string
+	mu     sync.RWMutex
+	data   map[string]mockEntry
 	prefix string
 }
 
+type mockEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (e mockEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
 func NewMockRedisClient(cfg *config.Config) (*MockRedisClient, error) {
 	return &MockRedisClient{
-		data:   make(map[string]string),
+		data:   make(map[string]mockEntry),
 		prefix: "news:",
 	}, nil
 }
@@ -25,18 +36,71 @@ func (m *MockRedisClient) Close() error {
 }
 
 func (m *MockRedisClient) IsProcessed(ctx context.Context, hash string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	key := m.prefix + hash
-	_, exists := m.data[key]
-	return exists, nil
+	entry, exists := m.data[key]
+	return exists && !entry.expired(time.Now()), nil
 }
 
 func (m *MockRedisClient) MarkProcessed(ctx context.Context, hash string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	key := m.prefix + hash
-	m.data[key] = "1"
+	m.data[key] = newMockEntry(processedValue, ttl)
+	return nil
+}
+
+func (m *MockRedisClient) Claim(ctx context.Context, hash string, ttl time.Duration) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := m.prefix + hash
+	if entry, exists := m.data[key]; exists && !entry.expired(time.Now()) {
+		return "", false, nil
+	}
+	token := newClaimToken()
+	m.data[key] = newMockEntry(token, ttl)
+	return token, true, nil
+}
+
+func (m *MockRedisClient) Release(ctx context.Context, hash, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := m.prefix + hash
+	if entry, exists := m.data[key]; exists && !entry.expired(time.Now()) && entry.value == token {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *MockRedisClient) CompleteClaim(ctx context.Context, hash, token string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := m.prefix + hash
+	if entry, exists := m.data[key]; exists && !entry.expired(time.Now()) && entry.value != token {
+		return nil
+	}
+	m.data[key] = newMockEntry(processedValue, ttl)
 	return nil
 }
 
 func (m *MockRedisClient) ClearProcessed(ctx context.Context) error {
-	m.data = make(map[string]string)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data = make(map[string]mockEntry)
 	return nil
 }
+
+func newMockEntry(value string, ttl time.Duration) mockEntry {
+	entry := mockEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	return entry
+}
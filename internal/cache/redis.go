@@ -2,6 +2,9 @@ package cache
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,10 +20,55 @@ type RedisClient struct {
 type RedisInterface interface {
 	IsProcessed(ctx context.Context, hash string) (bool, error)
 	MarkProcessed(ctx context.Context, hash string, ttl time.Duration) error
+	// Claim atomically reserves an unprocessed key for ttl and returns a
+	// token identifying the claim. It returns false if the key is already
+	// claimed or processed.
+	Claim(ctx context.Context, hash string, ttl time.Duration) (string, bool, error)
+	// Release drops the claim with the given token. Claims taken over by
+	// another run after the token's lease expired are left alone.
+	Release(ctx context.Context, hash, token string) error
+	// CompleteClaim marks a claimed key processed, unless another run has
+	// claimed it since the token's lease expired
+	CompleteClaim(ctx context.Context, hash, token string, ttl time.Duration) error
 	ClearProcessed(ctx context.Context) error
 	Close() error
 }
 
+// processedValue marks a processed key; claimed keys hold their claim token
+const processedValue = "1"
+
+// releaseScript deletes a key only while it still holds the caller's claim,
+// so a late release never removes a processed mark or another run's claim
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// completeScript marks a key processed while it holds the caller's claim or
+// nothing at all, so a run whose lease expired does not overwrite the claim
+// of the run that took over
+var completeScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value ~= false and value ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return redis.call("SET", KEYS[1], ARGV[2])
+`)
+
+// newClaimToken returns a random token identifying one claim
+func newClaimToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate claim token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
 func NewRedisClient(cfg *config.Config) (RedisInterface, error) {
 	// Try to create real Redis client first
 	opt, err := redis.ParseURL(cfg.RedisURL)
@@ -58,7 +106,34 @@ func (r *RedisClient) IsProcessed(ctx context.Context, hash string) (bool, error
 }
 
 func (r *RedisClient) MarkProcessed(ctx context.Context, hash string, ttl time.Duration) error {
-	return r.client.Set(ctx, r.prefix+hash, "1", ttl).Err()
+	return r.client.Set(ctx, r.prefix+hash, processedValue, ttl).Err()
+}
+
+func (r *RedisClient) Claim(ctx context.Context, hash string, ttl time.Duration) (string, bool, error) {
+	token := newClaimToken()
+	ok, err := r.client.SetNX(ctx, r.prefix+hash, token, ttl).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("redis setnx error: %w", err)
+	}
+	if !ok {
+		return "", false, nil
+	}
+	return token, true, nil
+}
+
+func (r *RedisClient) Release(ctx context.Context, hash, token string) error {
+	if err := releaseScript.Run(ctx, r.client, []string{r.prefix + hash}, token).Err(); err != nil {
+		return fmt.Errorf("redis release error: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisClient) CompleteClaim(ctx context.Context, hash, token string, ttl time.Duration) error {
+	err := completeScript.Run(ctx, r.client, []string{r.prefix + hash}, token, processedValue, ttl.Milliseconds()).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("redis complete claim error: %w", err)
+	}
+	return nil
 }
 
 func (r *RedisClient) ClearProcessed(ctx context.Context) error {
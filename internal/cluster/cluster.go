@@ -0,0 +1,282 @@
+package cluster
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// stopWords are ignored when building fingerprints
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "to": true, "was": true, "were": true,
+	"will": true, "with": true, "after": true, "over": true, "new": true, "news": true,
+}
+
+// Fingerprint is the set of normalized keywords describing an article
+type Fingerprint map[string]struct{}
+
+// NewFingerprint builds a fingerprint from the title, description, TLDR and tags of a news item
+func NewFingerprint(item *models.NewsItem) Fingerprint {
+	fp := make(Fingerprint)
+	add := func(text string) {
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, w := range words {
+			if len([]rune(w)) < 3 || stopWords[w] {
+				continue
+			}
+			fp[w] = struct{}{}
+		}
+	}
+
+	add(item.SeoTitle)
+	add(item.SeoDesc)
+	for _, point := range item.TLDR {
+		add(point)
+	}
+	for _, tag := range item.Tags {
+		add(tag)
+	}
+	return fp
+}
+
+// Similarity returns the Jaccard similarity of two fingerprints
+func (fp Fingerprint) Similarity(other Fingerprint) float64 {
+	if len(fp) == 0 || len(other) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range fp {
+		if _, ok := other[w]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(fp)+len(other)-shared)
+}
+
+type entry struct {
+	summary     models.RelatedNews
+	fingerprint Fingerprint
+}
+
+// Clusterer groups news items about the same event into clusters
+type Clusterer struct {
+	mu        sync.RWMutex
+	threshold float64
+	window    time.Duration
+	entries   map[string]*entry
+	clusters  map[string][]string
+}
+
+// NewClusterer creates a clusterer that joins items whose similarity reaches threshold
+// and whose creation times are at most window apart
+func NewClusterer(threshold float64, window time.Duration) *Clusterer {
+	return &Clusterer{
+		threshold: threshold,
+		window:    window,
+		entries:   make(map[string]*entry),
+		clusters:  make(map[string][]string),
+	}
+}
+
+// Load indexes already clustered items, e.g. those read from storage on startup.
+// Items without a cluster ID are assigned one; they are returned so the
+// caller can store their cluster ID and keep cluster URLs stable.
+func (c *Clusterer) Load(items []*models.NewsItem) []*models.NewsItem {
+	sorted := make([]*models.NewsItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	var assigned []*models.NewsItem
+	for _, item := range sorted {
+		if item.ClusterID == "" {
+			c.Assign(item)
+			assigned = append(assigned, item)
+			continue
+		}
+		c.mu.Lock()
+		c.add(item)
+		c.mu.Unlock()
+	}
+	return assigned
+}
+
+// Assign sets the cluster ID of the item, joining the most similar existing
+// cluster or starting a new one, and indexes the item. Equally similar
+// clusters are resolved in favour of the oldest, so the result does not
+// depend on map order.
+func (c *Clusterer) Assign(item *models.NewsItem) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fp := NewFingerprint(item)
+	best, bestScore := "", 0.0
+	for id, e := range c.entries {
+		if id == item.ID || !c.withinWindow(e.summary.CreatedAt, item.CreatedAt) {
+			continue
+		}
+		score := fp.Similarity(e.fingerprint)
+		if score < c.threshold || score < bestScore {
+			continue
+		}
+		if score > bestScore || c.older(e.summary.ClusterID, best) {
+			best, bestScore = e.summary.ClusterID, score
+		}
+	}
+
+	if best == "" {
+		best = item.ID
+	}
+	item.ClusterID = best
+	c.add(item)
+
+	return best
+}
+
+// Update refreshes the indexed summary of an item that keeps its cluster,
+// e.g. after an edit
+func (c *Clusterer) Update(item *models.NewsItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[item.ID]; ok {
+		c.add(item)
+	}
+}
+
+// Remove drops an item from the index, e.g. after it was deleted
+func (c *Clusterer) Remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return
+	}
+	delete(c.entries, id)
+
+	members := c.clusters[e.summary.ClusterID]
+	for i, member := range members {
+		if member == id {
+			members = append(members[:i], members[i+1:]...)
+			break
+		}
+	}
+	if len(members) == 0 {
+		delete(c.clusters, e.summary.ClusterID)
+	} else {
+		c.clusters[e.summary.ClusterID] = members
+	}
+}
+
+// Related returns up to limit other items in the same cluster as id, newest first
+func (c *Clusterer) Related(id string, limit int) []models.RelatedNews {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return []models.RelatedNews{}
+	}
+
+	timeline := c.timeline(e.summary.ClusterID)
+	related := make([]models.RelatedNews, 0, len(timeline))
+	for i := len(timeline) - 1; i >= 0; i-- {
+		if timeline[i].ID == id {
+			continue
+		}
+		related = append(related, timeline[i])
+		if limit > 0 && len(related) == limit {
+			break
+		}
+	}
+	return related
+}
+
+// Timeline returns the items of a cluster ordered from oldest to newest
+func (c *Clusterer) Timeline(clusterID string) ([]models.RelatedNews, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.clusters[clusterID]; !ok {
+		return nil, false
+	}
+	return c.timeline(clusterID), true
+}
+
+func (c *Clusterer) timeline(clusterID string) []models.RelatedNews {
+	members := c.clusters[clusterID]
+	items := make([]models.RelatedNews, 0, len(members))
+	for _, id := range members {
+		items = append(items, c.entries[id].summary)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items
+}
+
+// add indexes an item that already has a cluster ID; the caller must hold the lock
+func (c *Clusterer) add(item *models.NewsItem) {
+	if _, exists := c.entries[item.ID]; !exists {
+		c.clusters[item.ClusterID] = append(c.clusters[item.ClusterID], item.ID)
+	}
+	c.entries[item.ID] = &entry{
+		summary: models.RelatedNews{
+			ID:          item.ID,
+			ClusterID:   item.ClusterID,
+			SeoTitle:    item.SeoTitle,
+			SeoDesc:     item.SeoDesc,
+			Image:       item.Image,
+			OriginalUrl: item.OriginalUrl,
+			CreatedAt:   item.CreatedAt,
+			PublishedAt: item.PublishedAt,
+			Status:      item.CurrentStatus(),
+		},
+		fingerprint: NewFingerprint(item),
+	}
+}
+
+// older reports whether cluster a started before cluster b, breaking ties
+// by ID; the caller must hold the lock
+func (c *Clusterer) older(a, b string) bool {
+	if a == b {
+		return false
+	}
+	ta, tb := c.started(a), c.started(b)
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+	return a < b
+}
+
+// started returns the creation time of the oldest item of a cluster
+func (c *Clusterer) started(clusterID string) time.Time {
+	var first time.Time
+	for _, id := range c.clusters[clusterID] {
+		if t := c.entries[id].summary.CreatedAt; first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	return first
+}
+
+func (c *Clusterer) withinWindow(a, b time.Time) bool {
+	if c.window <= 0 {
+		return true
+	}
+	d := a.Sub(b)
+	if d < 0 {
+		d = -d
+	}
+	return d <= c.window
+}
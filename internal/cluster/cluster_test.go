@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func TestClustererGroupsSameStory(t *testing.T) {
+	now := time.Now()
+	c := NewClusterer(0.3, 72*time.Hour)
+
+	first := &models.NewsItem{
+		ID:        "1",
+		SeoTitle:  "Earthquake hits Izmir, buildings collapse",
+		TLDR:      []string{"Magnitude 6.6 earthquake struck Izmir", "Rescue teams search collapsed buildings"},
+		Tags:      []string{"earthquake", "izmir", "turkey"},
+		CreatedAt: now,
+	}
+	update := &models.NewsItem{
+		ID:        "2",
+		SeoTitle:  "Izmir earthquake death toll rises as rescue continues",
+		TLDR:      []string{"Rescue teams continue searching collapsed buildings in Izmir"},
+		Tags:      []string{"earthquake", "izmir", "rescue"},
+		CreatedAt: now.Add(6 * time.Hour),
+	}
+	unrelated := &models.NewsItem{
+		ID:        "3",
+		SeoTitle:  "Central bank raises interest rates",
+		TLDR:      []string{"Policy rate raised by 500 basis points"},
+		Tags:      []string{"economy", "inflation"},
+		CreatedAt: now.Add(7 * time.Hour),
+	}
+
+	if id := c.Assign(first); id != "1" {
+		t.Fatalf("Expected first item to start cluster 1, got %s", id)
+	}
+	if id := c.Assign(update); id != "1" {
+		t.Errorf("Expected follow-up to join cluster 1, got %s", id)
+	}
+	if id := c.Assign(unrelated); id != "3" {
+		t.Errorf("Expected unrelated item to start its own cluster, got %s", id)
+	}
+
+	related := c.Related("1", 10)
+	if len(related) != 1 || related[0].ID != "2" {
+		t.Errorf("Expected item 2 to be related to item 1, got %+v", related)
+	}
+
+	timeline, ok := c.Timeline("1")
+	if !ok || len(timeline) != 2 || timeline[0].ID != "1" || timeline[1].ID != "2" {
+		t.Errorf("Expected timeline [1 2], got %+v", timeline)
+	}
+
+	edited := *update
+	edited.SeoTitle = "Izmir earthquake: rescue ends"
+	c.Update(&edited)
+	if related := c.Related("1", 10); len(related) != 1 || related[0].SeoTitle != edited.SeoTitle {
+		t.Errorf("Expected the edited title in related items, got %+v", related)
+	}
+	if timeline, _ := c.Timeline("1"); len(timeline) != 2 {
+		t.Errorf("Expected an update to keep the timeline at 2 items, got %+v", timeline)
+	}
+
+	c.Remove("2")
+	if related := c.Related("1", 10); len(related) != 0 {
+		t.Errorf("Expected no related items after removal, got %+v", related)
+	}
+}
+
+func TestClustererRespectsWindow(t *testing.T) {
+	now := time.Now()
+	c := NewClusterer(0.3, 24*time.Hour)
+
+	c.Assign(&models.NewsItem{ID: "1", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now})
+	id := c.Assign(&models.NewsItem{ID: "2", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now.Add(48 * time.Hour)})
+	if id != "2" {
+		t.Errorf("Expected items outside the window to form separate clusters, got %s", id)
+	}
+}
+
+func TestClustererTiesGoToOldestCluster(t *testing.T) {
+	now := time.Now()
+	for run := 0; run < 20; run++ {
+		c := NewClusterer(0.3, 72*time.Hour)
+		c.Load([]*models.NewsItem{
+			{ID: "b", ClusterID: "b", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now.Add(time.Hour)},
+			{ID: "a", ClusterID: "a", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now},
+		})
+		item := &models.NewsItem{ID: "c", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now.Add(2 * time.Hour)}
+		if id := c.Assign(item); id != "a" {
+			t.Fatalf("Expected a tie to join the oldest cluster a, got %s", id)
+		}
+	}
+}
+
+func TestClustererLoadReturnsAssignedItems(t *testing.T) {
+	now := time.Now()
+	c := NewClusterer(0.3, 72*time.Hour)
+	assigned := c.Load([]*models.NewsItem{
+		{ID: "1", ClusterID: "1", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now},
+		{ID: "2", SeoTitle: "Istanbul marathon draws record crowd", CreatedAt: now.Add(time.Hour)},
+	})
+	if len(assigned) != 1 || assigned[0].ID != "2" || assigned[0].ClusterID != "1" {
+		t.Errorf("Expected item 2 to be assigned to cluster 1 and returned, got %+v", assigned)
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// CategoryReview is the bucket for news whose category could not be mapped
+// to the taxonomy; an editor assigns the right category later
+const CategoryReview = "review"
+
+// What happens to news whose category is not in the taxonomy
+const (
+	UnmappedReview = "review"
+	UnmappedReject = "reject"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Category is an entry of the canonical English category taxonomy
+type Category struct {
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+
+	// Labels are source category names, e.g. Turkish "Ekonomi", that map to
+	// this category for every source. Sources may add their own mapping.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Categories is the canonical category taxonomy
+type Categories struct {
+	List []Category `json:"categories"`
+
+	bySlug  map[string]Category
+	byLabel map[string]string
+}
+
+// defaultCategories is used when no categories file exists
+var defaultCategories = []Category{
+	{Slug: "world", Name: "World", Labels: []string{"Dünya", "Dış Haberler"}},
+	{Slug: "turkey", Name: "Turkey", Labels: []string{"Gündem", "Türkiye", "Yurt"}},
+	{Slug: "politics", Name: "Politics", Labels: []string{"Politika", "Siyaset"}},
+	{Slug: "economy", Name: "Economy", Labels: []string{"Ekonomi"}},
+	{Slug: "finance", Name: "Finance", Parent: "economy", Labels: []string{"Finans", "Borsa", "Piyasalar"}},
+	{Slug: "business", Name: "Business", Parent: "economy", Labels: []string{"İş Dünyası", "Şirketler"}},
+	{Slug: "technology", Name: "Technology", Labels: []string{"Teknoloji", "Bilim-Teknoloji"}},
+	{Slug: "science", Name: "Science", Labels: []string{"Bilim"}},
+	{Slug: "health", Name: "Health", Labels: []string{"Sağlık"}},
+	{Slug: "sports", Name: "Sports", Labels: []string{"Spor"}},
+	{Slug: "football", Name: "Football", Parent: "sports", Labels: []string{"Futbol"}},
+	{Slug: "culture", Name: "Culture & Arts", Labels: []string{"Kültür", "Kültür-Sanat", "Sanat"}},
+	{Slug: "entertainment", Name: "Entertainment", Labels: []string{"Magazin", "Eğlence"}},
+	{Slug: "travel", Name: "Travel", Labels: []string{"Seyahat", "Turizm"}},
+	{Slug: "environment", Name: "Environment", Labels: []string{"Çevre"}},
+	{Slug: "education", Name: "Education", Labels: []string{"Eğitim"}},
+	{Slug: "opinion", Name: "Opinion", Labels: []string{"Yazarlar", "Görüş"}},
+}
+
+// LoadCategories reads the taxonomy from a JSON file.
+// A missing file yields the built-in taxonomy.
+func LoadCategories(path string) (*Categories, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewCategories(defaultCategories...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read categories file: %w", err)
+	}
+
+	var file Categories
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse categories file %s: %w", path, err)
+	}
+	return NewCategories(file.List...)
+}
+
+// NewCategories builds a validated taxonomy
+func NewCategories(list ...Category) (*Categories, error) {
+	c := &Categories{
+		List:    list,
+		bySlug:  make(map[string]Category, len(list)),
+		byLabel: make(map[string]string),
+	}
+	for _, cat := range list {
+		if !slugPattern.MatchString(cat.Slug) {
+			return nil, fmt.Errorf("invalid category slug %q", cat.Slug)
+		}
+		if cat.Slug == CategoryReview {
+			return nil, fmt.Errorf("category slug %q is reserved", CategoryReview)
+		}
+		if cat.Name == "" {
+			return nil, fmt.Errorf("category %s has no name", cat.Slug)
+		}
+		if _, ok := c.bySlug[cat.Slug]; ok {
+			return nil, fmt.Errorf("duplicate category %s", cat.Slug)
+		}
+		c.bySlug[cat.Slug] = cat
+	}
+
+	for _, cat := range list {
+		if err := c.checkParents(cat); err != nil {
+			return nil, err
+		}
+		for _, name := range append([]string{cat.Name}, cat.Labels...) {
+			key := foldLabel(name)
+			if other, ok := c.byLabel[key]; ok && other != cat.Slug {
+				return nil, fmt.Errorf("label %q maps to both %s and %s", name, other, cat.Slug)
+			}
+			c.byLabel[key] = cat.Slug
+		}
+	}
+	return c, nil
+}
+
+// checkParents verifies that the parents of cat exist and do not form a cycle
+func (c *Categories) checkParents(cat Category) error {
+	seen := map[string]bool{cat.Slug: true}
+	for parent := cat.Parent; parent != ""; parent = c.bySlug[parent].Parent {
+		if _, ok := c.bySlug[parent]; !ok {
+			return fmt.Errorf("category %s has unknown parent %s", cat.Slug, parent)
+		}
+		if seen[parent] {
+			return fmt.Errorf("category %s has a parent cycle", cat.Slug)
+		}
+		seen[parent] = true
+	}
+	return nil
+}
+
+// Get returns the category with the given slug
+func (c *Categories) Get(slug string) (Category, bool) {
+	if c == nil {
+		return Category{}, false
+	}
+	cat, ok := c.bySlug[slug]
+	return cat, ok
+}
+
+// Slugs returns the slugs of all categories in taxonomy order
+func (c *Categories) Slugs() []string {
+	if c == nil {
+		return nil
+	}
+	slugs := make([]string, 0, len(c.List))
+	for _, cat := range c.List {
+		slugs = append(slugs, cat.Slug)
+	}
+	return slugs
+}
+
+// Resolve returns the slug a value refers to. The value may be a slug, a
+// display name or a label, compared case-insensitively with Turkish casing.
+func (c *Categories) Resolve(value string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	if _, ok := c.bySlug[strings.ToLower(value)]; ok {
+		return strings.ToLower(value), true
+	}
+	slug, ok := c.byLabel[foldLabel(value)]
+	return slug, ok
+}
+
+// Map returns the canonical slug of a source category label. The mapping of
+// the source wins over the labels of the taxonomy.
+func (c *Categories) Map(src Source, label string) (string, bool) {
+	key := foldLabel(label)
+	if key == "" {
+		return "", false
+	}
+	for from, slug := range src.CategoryMap {
+		if foldLabel(from) == key {
+			return slug, true
+		}
+	}
+	return c.Resolve(label)
+}
+
+// checkSources verifies that the category maps of the sources point at known categories
+func (c *Categories) checkSources(sources *Sources) error {
+	if sources == nil {
+		return nil
+	}
+	check := func(name string, src Source) error {
+		labels := make([]string, 0, len(src.CategoryMap))
+		for label := range src.CategoryMap {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			if slug := src.CategoryMap[label]; slug != CategoryReview {
+				if _, ok := c.bySlug[slug]; !ok {
+					return fmt.Errorf("category_map of %s maps %q to unknown category %q", name, label, slug)
+				}
+			}
+		}
+		return nil
+	}
+	if err := check("defaults", sources.Defaults); err != nil {
+		return err
+	}
+	for _, src := range sources.List {
+		if err := check(src.URL, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// foldLabel lowercases a label with Turkish casing rules and collapses whitespace
+func foldLabel(s string) string {
+	return strings.Join(strings.Fields(strings.ToLowerSpecial(unicode.TurkishCase, s)), " ")
+}
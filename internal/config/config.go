@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -17,11 +19,14 @@ type Config struct {
 	Env             string        `json:"env"`
 	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
 	HTTPTimeout     time.Duration `json:"http_timeout"`
+	// PublicURL is the externally reachable base URL, used for WebSub callbacks
+	PublicURL string `json:"public_url"`
 
 	// Redis configuration
 	RedisURL       string `json:"redis_url"`
 	RedisPrefix    string `json:"redis_prefix"`
 	CacheTTL       time.Duration `json:"cache_ttl"`
+	ClaimTTL       time.Duration `json:"claim_ttl"`
 	MaxConcurrency int    `json:"max_concurrency"`
 
 	// CloudFlare R2 Configuration
@@ -30,6 +35,8 @@ type Config struct {
 	R2SecretKey     string `json:"r2_secret_key"`
 	R2Bucket        string `json:"r2_bucket"`
 	R2AccountID     string `json:"r2_account_id"`
+	// R2IndexPath is the metadata index of the R2 backend; empty disables it
+	R2IndexPath string `json:"r2_index_path"`
 
 	// AI Configuration
 	AIApiKey    string `json:"ai_api_key"`
@@ -40,9 +47,55 @@ type Config struct {
 	// Storage
 	StoragePath    string `json:"storage_path"`
 	FeedSourcePath string `json:"feed_source_path"`
+	// FeedWatchInterval is how often FeedSourcePath is scanned for dropped
+	// feed files; 0 disables the watcher
+	FeedWatchInterval time.Duration `json:"feed_watch_interval"`
 	ProcessedPath  string `json:"processed_path"`
 	RetentionDays  int    `json:"retention_days"`
 	MaxFileSize    int64  `json:"max_file_size"`
+	// StorageBackend is the primary news store ("filesystem" or "r2");
+	// StorageMirrors receive a copy of every change
+	StorageBackend string   `json:"storage_backend"`
+	StorageMirrors []string `json:"storage_mirrors"`
+	// StorageIndexPath is the metadata index of the filesystem backend;
+	// empty disables it
+	StorageIndexPath string `json:"storage_index_path"`
+	// SearchIndexPath keeps the full-text search index on disk; empty keeps
+	// it in memory and rebuilds it on every start
+	SearchIndexPath string `json:"search_index_path"`
+
+	// Feed fetching
+	FeedMaxConcurrent   int           `json:"feed_max_concurrent"`
+	FeedRequestTimeout  time.Duration `json:"feed_request_timeout"`
+	FeedMaxRetries      int           `json:"feed_max_retries"`
+	FeedUserAgent       string        `json:"feed_user_agent"`
+	FeedHostInterval    time.Duration `json:"feed_host_interval"`
+	FeedMaxResponseSize int64         `json:"feed_max_response_size"`
+
+	// Feed health: failing feeds back off exponentially and are disabled
+	// after FeedDisableAfter consecutive failures (0 never disables)
+	FeedHealthPath   string        `json:"feed_health_path"`
+	FeedBackoffBase  time.Duration `json:"feed_backoff_base"`
+	FeedBackoffMax   time.Duration `json:"feed_backoff_max"`
+	FeedDisableAfter int           `json:"feed_disable_after"`
+
+	// Feed sources
+	SourcesPath string   `json:"sources_path"`
+	Sources     *Sources `json:"-"`
+
+	// Category taxonomy. UnmappedCategory decides what happens to news whose
+	// category is not in the taxonomy: "review" or "reject".
+	CategoriesPath   string      `json:"categories_path"`
+	Categories       *Categories `json:"-"`
+	UnmappedCategory string      `json:"unmapped_category"`
+
+	// URLStripParams lists query parameters removed when canonicalizing
+	// article URLs; a trailing "*" matches by prefix
+	URLStripParams []string `json:"url_strip_params"`
+
+	// Story clustering
+	ClusterThreshold float64       `json:"cluster_threshold"`
+	ClusterWindow    time.Duration `json:"cluster_window"`
 
 	// Logging
 	LogLevel string `json:"log_level"`
@@ -65,11 +118,13 @@ func Load() *Config {
 		Env:             getEnv("APP_ENV", "development"),
 		ShutdownTimeout: getEnvAsDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
 		HTTPTimeout:     getEnvAsDuration("HTTP_TIMEOUT", 30*time.Second),
+		PublicURL:       getEnv("PUBLIC_URL", ""),
 
 		// Redis configuration
 		RedisURL:       getEnv("REDIS_URL", "redis://localhost:6379/0"),
 		RedisPrefix:    getEnv("REDIS_PREFIX", "ai-news:"),
 		CacheTTL:       getEnvAsDuration("CACHE_TTL", 720*time.Hour), // 30 days
+		ClaimTTL:       getEnvAsDuration("CLAIM_TTL", 30*time.Minute),
 		MaxConcurrency: getEnvAsInt("MAX_CONCURRENCY", 5),
 
 		// AI Configuration
@@ -81,9 +136,39 @@ func Load() *Config {
 		// Storage
 		StoragePath:    getEnv("STORAGE_PATH", "./data"),
 		FeedSourcePath: getEnv("FEED_SOURCE_PATH", "./data/feeds/"),
+		FeedWatchInterval: getEnvAsDuration("FEED_WATCH_INTERVAL", 30*time.Second),
 		ProcessedPath:  getEnv("PROCESSED_PATH", "./data/processed/"),
 		MaxFileSize:    getEnvAsInt64("MAX_FILE_SIZE", 10<<20), // 10MB
 		RetentionDays:  getEnvAsInt("RETENTION_DAYS", 30),
+		StorageBackend: getEnv("STORAGE_BACKEND", "filesystem"),
+		StorageMirrors: getEnvAsSlice("STORAGE_MIRRORS", []string{"r2"}),
+		StorageIndexPath: getEnv("STORAGE_INDEX_PATH", "./data/index.db"),
+		SearchIndexPath:  getEnv("SEARCH_INDEX_PATH", "./data/search.db"),
+
+		// Feed fetching
+		FeedMaxConcurrent:   getEnvAsInt("FEED_MAX_CONCURRENT", 5),
+		FeedRequestTimeout:  getEnvAsDuration("FEED_REQUEST_TIMEOUT", 30*time.Second),
+		FeedMaxRetries:      getEnvAsInt("FEED_MAX_RETRIES", 3),
+		FeedUserAgent:       getEnv("FEED_USER_AGENT", "AI-News-Processor/1.0"),
+		FeedHostInterval:    getEnvAsDuration("FEED_HOST_INTERVAL", time.Second),
+		FeedMaxResponseSize: getEnvAsInt64("FEED_MAX_RESPONSE_SIZE", 5<<20), // 5MB
+
+		// Feed health
+		FeedHealthPath:   getEnv("FEED_HEALTH_PATH", "./data/feed_health.json"),
+		FeedBackoffBase:  getEnvAsDuration("FEED_BACKOFF_BASE", time.Minute),
+		FeedBackoffMax:   getEnvAsDuration("FEED_BACKOFF_MAX", 6*time.Hour),
+		FeedDisableAfter: getEnvAsInt("FEED_DISABLE_AFTER", 10),
+
+		// Feed sources
+		SourcesPath: getEnv("SOURCES_PATH", "./sources.json"),
+		URLStripParams: getEnvAsSlice("URL_STRIP_PARAMS", []string{
+			"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid", "igshid",
+			"mc_cid", "mc_eid", "_ga", "ref", "ref_src", "amp", "outputtype",
+		}),
+
+		// Category taxonomy
+		CategoriesPath:   getEnv("CATEGORIES_PATH", "./categories.json"),
+		UnmappedCategory: getEnv("UNMAPPED_CATEGORY", UnmappedReview),
 
 		// CloudFlare R2 Configuration
 		R2Endpoint:  getEnv("R2_ENDPOINT", ""),
@@ -91,6 +176,11 @@ func Load() *Config {
 		R2SecretKey: getEnv("R2_SECRET_ACCESS_KEY", ""),
 		R2Bucket:    getEnv("R2_BUCKET", "newsapi"),
 		R2AccountID: getEnv("CLOUDFLARE_ACCOUNT_ID", ""),
+		R2IndexPath: getEnv("R2_INDEX_PATH", "./data/r2_index.db"),
+
+		// Story clustering
+		ClusterThreshold: getEnvAsFloat("CLUSTER_THRESHOLD", 0.35),
+		ClusterWindow:    getEnvAsDuration("CLUSTER_WINDOW", 72*time.Hour),
 
 		// Logging
 		LogLevel: getEnv("LOG_LEVEL", "info"),
@@ -100,6 +190,23 @@ func Load() *Config {
 		AdminAPIKey: getEnv("ADMIN_API_KEY", ""),
 	}
 
+	// Load per-source feed settings
+	sources, err := LoadSources(cfg.SourcesPath)
+	if err != nil {
+		log.Fatalf("Invalid sources configuration: %v", err)
+	}
+	cfg.Sources = sources
+
+	// Load the category taxonomy
+	categories, err := LoadCategories(cfg.CategoriesPath)
+	if err != nil {
+		log.Fatalf("Invalid categories configuration: %v", err)
+	}
+	if err := categories.checkSources(sources); err != nil {
+		log.Fatalf("Invalid sources configuration: %v", err)
+	}
+	cfg.Categories = categories
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		log.Fatalf("Invalid configuration: %v", err)
@@ -110,7 +217,11 @@ func Load() *Config {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	// Add validation logic here
+	switch c.UnmappedCategory {
+	case UnmappedReview, UnmappedReject:
+	default:
+		return fmt.Errorf("UNMAPPED_CATEGORY must be %q or %q, got %q", UnmappedReview, UnmappedReject, c.UnmappedCategory)
+	}
 	return nil
 }
 
@@ -148,6 +259,33 @@ func getEnvAsInt64(name string, defaultVal int64) int64 {
 	return value
 }
 
+func getEnvAsFloat(name string, defaultVal float64) float64 {
+	valueStr := getEnv(name, "")
+	if valueStr == "" {
+		return defaultVal
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		log.Printf("Invalid %s value: %v, using default: %v", name, err, defaultVal)
+		return defaultVal
+	}
+	return value
+}
+
+func getEnvAsSlice(name string, defaultVal []string) []string {
+	valueStr := getEnv(name, "")
+	if valueStr == "" {
+		return defaultVal
+	}
+	var values []string
+	for _, v := range strings.Split(valueStr, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func getEnvAsDuration(name string, defaultVal time.Duration) time.Duration {
 	valueStr := getEnv(name, "")
 	if valueStr == "" {
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Item identity strategies used to decide whether two feed items are the same
+const (
+	IdentityGUID    = "guid"
+	IdentityURL     = "url"
+	IdentityContent = "content"
+)
+
+// Pre-fetch hook types
+const (
+	HookWarmUp     = "warmup"
+	HookAuthHeader = "auth_header"
+	HookCookie     = "cookie"
+)
+
+// Duration is a time.Duration that reads from JSON strings such as "2s"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(value)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// HookConfig configures a hook that runs before a feed is fetched
+type HookConfig struct {
+	// Type is one of "warmup", "auth_header" or "cookie"
+	Type string `json:"type"`
+	// Hosts restricts the hook to feed hosts equal to or ending in one of
+	// these domains; empty means every host
+	Hosts []string `json:"hosts,omitempty"`
+
+	// URL is the warm-up ping or cookie bootstrap address. Warm-up defaults
+	// to the feed's scheme and host.
+	URL string `json:"url,omitempty"`
+	// PollInterval and Timeout bound warm-up readiness polling
+	PollInterval Duration `json:"poll_interval,omitempty"`
+	Timeout      Duration `json:"timeout,omitempty"`
+	// TTL is how long a host stays warm or bootstrapped cookies are reused
+	TTL Duration `json:"ttl,omitempty"`
+
+	// Header and Value (or ValueEnv, the name of an environment variable
+	// holding the value) are injected by auth_header hooks
+	Header   string `json:"header,omitempty"`
+	Value    string `json:"value,omitempty"`
+	ValueEnv string `json:"value_env,omitempty"`
+}
+
+// FilterConfig selects which items of a source are sent to the model.
+// Keywords, categories and title phrases match case-insensitively; patterns
+// are Go regular expressions matched against the title and content.
+type FilterConfig struct {
+	// IncludeKeywords and IncludePatterns, when set, require a match in the
+	// title or content; ExcludeKeywords and ExcludePatterns reject on a match
+	IncludeKeywords []string `json:"include_keywords,omitempty"`
+	ExcludeKeywords []string `json:"exclude_keywords,omitempty"`
+	IncludePatterns []string `json:"include_patterns,omitempty"`
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+
+	// AllowCategories, when set, lists the only accepted categories;
+	// DenyCategories rejects the listed ones
+	AllowCategories []string `json:"allow_categories,omitempty"`
+	DenyCategories  []string `json:"deny_categories,omitempty"`
+
+	// MinLength is the minimum content length in characters
+	MinLength int `json:"min_length,omitempty"`
+	// MaxAge rejects items published longer ago; items without a date pass
+	MaxAge Duration `json:"max_age,omitempty"`
+
+	// TitleBlacklist rejects items whose title contains one of these phrases
+	TitleBlacklist []string `json:"title_blacklist,omitempty"`
+}
+
+// IngestConfig lets a publisher push items of a source to POST /api/v1/ingest
+type IngestConfig struct {
+	// Publisher is the ID sent in the X-Publisher-ID header
+	Publisher string `json:"publisher"`
+	// Secret (or SecretEnv, the name of an environment variable holding it)
+	// is the HMAC-SHA256 key the publisher signs pushes with
+	Secret    string `json:"secret,omitempty"`
+	SecretEnv string `json:"secret_env,omitempty"`
+}
+
+// SecretValue returns the configured secret, reading SecretEnv if set
+func (c IngestConfig) SecretValue() string {
+	if c.SecretEnv != "" {
+		return os.Getenv(c.SecretEnv)
+	}
+	return c.Secret
+}
+
+// WebSubConfig subscribes to a source at a WebSub hub so updates are pushed
+type WebSubConfig struct {
+	Hub string `json:"hub"`
+	// Topic defaults to the source URL
+	Topic string `json:"topic,omitempty"`
+	// Lease is the requested subscription lease; the hub may grant another
+	Lease Duration `json:"lease,omitempty"`
+}
+
+// renderWarmUp wakes services on Render's free tier, which sleep when idle.
+// It applies to every source unless the defaults set their own hooks.
+var renderWarmUp = HookConfig{
+	Type:         HookWarmUp,
+	Hosts:        []string{"onrender.com"},
+	PollInterval: Duration(2 * time.Second),
+	Timeout:      Duration(60 * time.Second),
+	TTL:          Duration(5 * time.Minute),
+}
+
+// Source holds the settings of a single feed source
+type Source struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+
+	// Identity selects how items of this source are recognised as duplicates:
+	// "guid" (default), "url" or "content"
+	Identity string `json:"identity"`
+
+	// ResolveCanonical fetches each article page to follow its
+	// <link rel="canonical"> before hashing and storing the URL
+	ResolveCanonical bool `json:"resolve_canonical"`
+
+	// ExtractArticle fetches the article page and replaces teaser content
+	// with the extracted main text. Extraction only runs when the feed
+	// content is shorter than ExtractMinLength characters (0 means always).
+	ExtractArticle   bool `json:"extract_article"`
+	ExtractMinLength int  `json:"extract_min_length"`
+
+	// MarkdownContent keeps headings, lists, quotes and links of the feed
+	// content as Markdown instead of plain text
+	MarkdownContent bool `json:"markdown_content"`
+
+	// Hooks run before each fetch of the feed, e.g. to wake the service
+	// up or authenticate. A nil list inherits the defaults.
+	Hooks []HookConfig `json:"hooks"`
+
+	// Filters reject unwanted items before they are deduplicated and sent to
+	// the model. A nil value inherits the defaults.
+	Filters *FilterConfig `json:"filters,omitempty"`
+
+	// CategoryMap maps category labels of this source, e.g. "Ekonomi", to
+	// taxonomy slugs, taking precedence over the labels of the taxonomy.
+	// A nil map inherits the defaults.
+	CategoryMap map[string]string `json:"category_map,omitempty"`
+
+	// AutoPublish publishes generated items right away; false holds them in
+	// review until an editor approves them. A nil value inherits the
+	// defaults, which publish.
+	AutoPublish *bool `json:"auto_publish,omitempty"`
+
+	// Ingest accepts signed pushes from the publisher of this source
+	Ingest *IngestConfig `json:"ingest,omitempty"`
+	// WebSub subscribes to the source at a WebSub hub
+	WebSub *WebSubConfig `json:"websub,omitempty"`
+}
+
+// Sources is the registry of configured feed sources, keyed by feed URL
+type Sources struct {
+	Defaults Source   `json:"defaults"`
+	List     []Source `json:"sources"`
+
+	byURL       map[string]Source
+	byPublisher map[string]Source
+}
+
+// LoadSources reads the source registry from a JSON file.
+// A missing file yields an empty registry that applies the defaults to every feed.
+func LoadSources(path string) (*Sources, error) {
+	sources := &Sources{}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read sources file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, sources); err != nil {
+			return nil, fmt.Errorf("failed to parse sources file %s: %w", path, err)
+		}
+	}
+
+	return NewSources(sources.Defaults, sources.List...)
+}
+
+// NewSources builds a validated source registry from defaults and per-source settings
+func NewSources(defaults Source, list ...Source) (*Sources, error) {
+	sources := &Sources{Defaults: defaults, List: list}
+	if err := sources.init(); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// init applies defaults, validates and indexes the configured sources
+func (s *Sources) init() error {
+	if s.Defaults.Identity == "" {
+		s.Defaults.Identity = IdentityGUID
+	}
+	if s.Defaults.Hooks == nil {
+		s.Defaults.Hooks = []HookConfig{renderWarmUp}
+	}
+	if err := validateSource(s.Defaults); err != nil {
+		return fmt.Errorf("invalid source defaults: %w", err)
+	}
+	if s.Defaults.Ingest != nil || s.Defaults.WebSub != nil {
+		return fmt.Errorf("invalid source defaults: ingest and websub are set per source")
+	}
+
+	s.byURL = make(map[string]Source, len(s.List))
+	s.byPublisher = make(map[string]Source)
+	for i, src := range s.List {
+		if src.URL == "" {
+			return fmt.Errorf("source %d (%s) has no url", i, src.Name)
+		}
+		src = s.withDefaults(src)
+		if err := validateSource(src); err != nil {
+			return fmt.Errorf("invalid source %s: %w", src.URL, err)
+		}
+		s.List[i] = src
+		s.byURL[normalizeSourceURL(src.URL)] = src
+
+		if src.Ingest != nil {
+			if _, ok := s.byPublisher[src.Ingest.Publisher]; ok {
+				return fmt.Errorf("publisher %s is used by more than one source", src.Ingest.Publisher)
+			}
+			s.byPublisher[src.Ingest.Publisher] = src
+		}
+	}
+	return nil
+}
+
+// Get returns the settings for the given feed URL, falling back to the defaults
+func (s *Sources) Get(feedURL string) Source {
+	if s == nil {
+		return Source{URL: feedURL, Identity: IdentityGUID, Hooks: []HookConfig{renderWarmUp}}
+	}
+	if src, ok := s.byURL[normalizeSourceURL(feedURL)]; ok {
+		return src
+	}
+	src := s.Defaults
+	src.URL = feedURL
+	return src
+}
+
+// Has reports whether the feed URL has its own entry in the registry
+func (s *Sources) Has(feedURL string) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.byURL[normalizeSourceURL(feedURL)]
+	return ok
+}
+
+// Publisher returns the source that accepts pushes from the given publisher
+func (s *Sources) Publisher(id string) (Source, bool) {
+	if s == nil || id == "" {
+		return Source{}, false
+	}
+	src, ok := s.byPublisher[id]
+	return src, ok
+}
+
+// withDefaults fills unset fields of src from the registry defaults
+func (s *Sources) withDefaults(src Source) Source {
+	if src.Identity == "" {
+		src.Identity = s.Defaults.Identity
+	}
+	if src.Hooks == nil {
+		src.Hooks = s.Defaults.Hooks
+	}
+	if src.Filters == nil {
+		src.Filters = s.Defaults.Filters
+	}
+	if src.CategoryMap == nil {
+		src.CategoryMap = s.Defaults.CategoryMap
+	}
+	if src.AutoPublish == nil {
+		src.AutoPublish = s.Defaults.AutoPublish
+	}
+	return src
+}
+
+// Publishes reports whether items generated from the source are published
+// without review
+func (src Source) Publishes() bool {
+	return src.AutoPublish == nil || *src.AutoPublish
+}
+
+func validateSource(src Source) error {
+	switch src.Identity {
+	case IdentityGUID, IdentityURL, IdentityContent:
+	default:
+		return fmt.Errorf("unknown identity strategy %q", src.Identity)
+	}
+	for i, hook := range src.Hooks {
+		switch hook.Type {
+		case HookWarmUp:
+		case HookAuthHeader:
+			if hook.Header == "" || (hook.Value == "" && hook.ValueEnv == "") {
+				return fmt.Errorf("hook %d: auth_header needs header and value or value_env", i)
+			}
+		case HookCookie:
+			if hook.URL == "" {
+				return fmt.Errorf("hook %d: cookie needs a bootstrap url", i)
+			}
+		default:
+			return fmt.Errorf("hook %d: unknown type %q", i, hook.Type)
+		}
+	}
+	if src.Filters != nil {
+		patterns := append(append([]string{}, src.Filters.IncludePatterns...), src.Filters.ExcludePatterns...)
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+			}
+		}
+		if src.Filters.MinLength < 0 || src.Filters.MaxAge < 0 {
+			return fmt.Errorf("filter min_length and max_age must not be negative")
+		}
+	}
+	if src.Ingest != nil {
+		if src.Ingest.Publisher == "" || (src.Ingest.Secret == "" && src.Ingest.SecretEnv == "") {
+			return fmt.Errorf("ingest needs publisher and secret or secret_env")
+		}
+	}
+	if src.WebSub != nil && src.WebSub.Hub == "" {
+		return fmt.Errorf("websub needs a hub")
+	}
+	return nil
+}
+
+func normalizeSourceURL(u string) string {
+	return strings.TrimRight(strings.TrimSpace(u), "/")
+}
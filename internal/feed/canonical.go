@@ -0,0 +1,202 @@
+package feed
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Canonicalizer normalizes article URLs so that variants of the same article
+// (tracking parameters, AMP pages, scheme and host case differences) map to one URL
+type Canonicalizer struct {
+	stripExact  map[string]bool
+	stripPrefix []string
+}
+
+// NewCanonicalizer creates a canonicalizer that removes the given query parameters.
+// A trailing "*" matches parameters by prefix, e.g. "utm_*".
+func NewCanonicalizer(stripParams []string) *Canonicalizer {
+	c := &Canonicalizer{stripExact: make(map[string]bool)}
+	for _, param := range stripParams {
+		param = strings.ToLower(strings.TrimSpace(param))
+		if param == "" {
+			continue
+		}
+		if strings.HasSuffix(param, "*") {
+			c.stripPrefix = append(c.stripPrefix, strings.TrimSuffix(param, "*"))
+			continue
+		}
+		c.stripExact[param] = true
+	}
+	return c
+}
+
+// Canonicalize returns the normalized form of an absolute http(s) URL
+func (c *Canonicalizer) Canonicalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL %q: %w", raw, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL %q is not absolute", raw)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	u.Scheme = "https"
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "amp.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+	u.Host = host
+
+	u.Path = c.canonicalPath(u.Path)
+	u.RawPath = ""
+	u.RawQuery = c.canonicalQuery(u.Query())
+	u.Fragment = ""
+	u.User = nil
+
+	return u.String(), nil
+}
+
+// canonicalPath removes AMP markers, duplicate and trailing slashes
+func (c *Canonicalizer) canonicalPath(p string) string {
+	if p == "" {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(path.Clean(p), "/"), "/")
+	if len(segments) > 0 && strings.EqualFold(segments[0], "amp") {
+		segments = segments[1:]
+	}
+	if n := len(segments); n > 0 && strings.EqualFold(segments[n-1], "amp") {
+		segments = segments[:n-1]
+	}
+	if n := len(segments); n > 0 {
+		last := segments[n-1]
+		switch lower := strings.ToLower(last); {
+		case strings.HasSuffix(lower, ".amp.html"):
+			segments[n-1] = last[:len(last)-len(".amp.html")] + ".html"
+		case strings.HasSuffix(lower, ".amp"):
+			segments[n-1] = last[:len(last)-len(".amp")]
+		}
+	}
+
+	cleaned := strings.Join(segments, "/")
+	if cleaned == "" || cleaned == "." {
+		return ""
+	}
+	return "/" + cleaned
+}
+
+// canonicalQuery drops stripped parameters and sorts the rest for a stable encoding
+func (c *Canonicalizer) canonicalQuery(query url.Values) string {
+	for key := range query {
+		if c.shouldStrip(key) {
+			query.Del(key)
+		}
+	}
+	if len(query) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(key))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
+
+func (c *Canonicalizer) shouldStrip(key string) bool {
+	key = strings.ToLower(key)
+	if c.stripExact[key] {
+		return true
+	}
+	for _, prefix := range c.stripPrefix {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromHTML returns the canonical URL of an article page, taken from its
+// <link rel="canonical"> element and resolved against pageURL. It falls
+// back to the canonical form of pageURL itself.
+func (c *Canonicalizer) FromHTML(pageURL string, body io.Reader) (string, error) {
+	if href := findCanonicalLink(body); href != "" {
+		base, err := url.Parse(pageURL)
+		if err == nil {
+			if ref, err := base.Parse(href); err == nil {
+				if canonical, err := c.Canonicalize(ref.String()); err == nil {
+					return canonical, nil
+				}
+			}
+		}
+	}
+	return c.Canonicalize(pageURL)
+}
+
+// findCanonicalLink scans the document head for a canonical link
+func findCanonicalLink(body io.Reader) string {
+	z := html.NewTokenizer(body)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := string(name)
+			if tag == "body" {
+				return ""
+			}
+			if tag != "link" || !hasAttr {
+				continue
+			}
+			var rel, href string
+			for {
+				key, val, more := z.TagAttr()
+				switch string(bytes.ToLower(key)) {
+				case "rel":
+					rel = strings.ToLower(string(val))
+				case "href":
+					href = strings.TrimSpace(string(val))
+				}
+				if !more {
+					break
+				}
+			}
+			for _, r := range strings.Fields(rel) {
+				if r == "canonical" && href != "" {
+					return href
+				}
+			}
+		}
+	}
+}
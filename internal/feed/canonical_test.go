@@ -0,0 +1,58 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	c := NewCanonicalizer([]string{"utm_*", "fbclid", "amp"})
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"http://WWW.Example.com/haber/123/", "https://www.example.com/haber/123"},
+		{"https://example.com/haber/123?utm_source=x&utm_medium=y&fbclid=abc", "https://example.com/haber/123"},
+		{"https://example.com/haber/123?b=2&a=1#comments", "https://example.com/haber/123?a=1&b=2"},
+		{"https://example.com/amp/haber/123", "https://example.com/haber/123"},
+		{"https://example.com/haber/123/amp/", "https://example.com/haber/123"},
+		{"https://example.com/haber-123.amp.html", "https://example.com/haber-123.html"},
+		{"https://amp.example.com/haber/123?amp=1", "https://example.com/haber/123"},
+		{"https://example.com:443/haber//123", "https://example.com/haber/123"},
+	}
+
+	for _, tt := range tests {
+		got, err := c.Canonicalize(tt.in)
+		if err != nil {
+			t.Errorf("Canonicalize(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalFromHTML(t *testing.T) {
+	c := NewCanonicalizer([]string{"utm_*"})
+	page := `<html><head><title>Haber</title>
+<link rel="canonical" href="/haber/123?utm_source=rss"></head>
+<body><p>İçerik</p></body></html>`
+
+	got, err := c.FromHTML("https://m.example.com/haber/123-amp", strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("FromHTML returned error: %v", err)
+	}
+	if want := "https://m.example.com/haber/123"; got != want {
+		t.Errorf("FromHTML = %q, want %q", got, want)
+	}
+
+	got, err = c.FromHTML("https://example.com/haber/456/", strings.NewReader("<html><body></body></html>"))
+	if err != nil {
+		t.Fatalf("FromHTML returned error: %v", err)
+	}
+	if want := "https://example.com/haber/456"; got != want {
+		t.Errorf("FromHTML without canonical link = %q, want %q", got, want)
+	}
+}
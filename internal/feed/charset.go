@@ -0,0 +1,142 @@
+package feed
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var (
+	xmlEncodingRegex = regexp.MustCompile(`(?i)^\s*<\?xml[^>]*\sencoding\s*=\s*["']([\w.:-]+)["']`)
+	metaCharsetRegex = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([\w.:-]+)`)
+
+	// mojibakeRegex matches UTF-8 Turkish letters that were decoded as Latin-1/Windows-1252
+	mojibakeRegex = regexp.MustCompile(`Ã[¼¶§‡–œ]|Ä[±°Ÿž]|Å[Ÿž]`)
+)
+
+// sniffLength is how much of a document is searched for a declared charset
+const sniffLength = 1024
+
+// DecodeBody converts a response body to UTF-8. The charset is taken from the
+// byte order mark, the Content-Type header, the XML prolog or an HTML meta tag,
+// in that order. Undeclared bodies that are not valid UTF-8 are sniffed as one
+// of the legacy Turkish encodings. It returns the decoded body and the charset used.
+func DecodeBody(body []byte, contentType string) ([]byte, string, error) {
+	name := detectCharset(body, contentType)
+	if name == "utf-8" {
+		return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), name, nil
+	}
+
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, name, err
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to decode %s body: %w", name, err)
+	}
+	return decoded, name, nil
+}
+
+// detectCharset returns the normalized name of the body's charset
+func detectCharset(body []byte, contentType string) string {
+	switch {
+	case bytes.HasPrefix(body, []byte("\xef\xbb\xbf")):
+		return "utf-8"
+	case bytes.HasPrefix(body, []byte("\xfe\xff")):
+		return "utf-16be"
+	case bytes.HasPrefix(body, []byte("\xff\xfe")):
+		return "utf-16le"
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if name := normalizeCharset(params["charset"]); name != "" {
+			return name
+		}
+	}
+
+	head := body
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+	if m := xmlEncodingRegex.FindSubmatch(head); m != nil {
+		if name := normalizeCharset(string(m[1])); name != "" {
+			return name
+		}
+	}
+	if m := metaCharsetRegex.FindSubmatch(head); m != nil {
+		if name := normalizeCharset(string(m[1])); name != "" {
+			return name
+		}
+	}
+
+	return sniffCharset(body)
+}
+
+// sniffCharset guesses the encoding of an undeclared body. Valid UTF-8 is
+// taken as is; otherwise bytes in 0x80-0x9F, which are control characters in
+// ISO-8859-9 but printable in Windows-1254, decide between the two.
+func sniffCharset(body []byte) string {
+	if utf8.Valid(body) {
+		return "utf-8"
+	}
+	for _, b := range body {
+		if b >= 0x80 && b <= 0x9f {
+			return "windows-1254"
+		}
+	}
+	return "iso-8859-9"
+}
+
+// normalizeCharset maps charset labels to canonical names, or "" if unknown
+func normalizeCharset(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return ""
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return ""
+	}
+	name, err := htmlindex.Name(enc)
+	if err != nil {
+		return ""
+	}
+	// The WHATWG index maps ISO-8859-9 to its Windows-1254 superset
+	if label == "iso-8859-9" || label == "latin5" || label == "l5" {
+		return "iso-8859-9"
+	}
+	return name
+}
+
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	switch name {
+	case "iso-8859-9":
+		return charmap.ISO8859_9, nil
+	case "windows-1254":
+		return charmap.Windows1254, nil
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), nil
+	case "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q: %w", name, err)
+	}
+	return enc, nil
+}
+
+// hasEncodingDamage reports whether text contains invalid UTF-8, replacement
+// characters or typical double-encoding sequences
+func hasEncodingDamage(s string) bool {
+	return !utf8.ValidString(s) || strings.ContainsRune(s, utf8.RuneError) || mojibakeRegex.MatchString(s)
+}
@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDecodeBody(t *testing.T) {
+	const title = "Güneş İzmir'de doğdu, şehir ışıl ışıl"
+
+	latin5, err := charmap.ISO8859_9.NewEncoder().String(title)
+	if err != nil {
+		t.Fatalf("Failed to encode fixture: %v", err)
+	}
+	cp1254, err := charmap.Windows1254.NewEncoder().String(title + " “alıntı”")
+	if err != nil {
+		t.Fatalf("Failed to encode fixture: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantCharset string
+		want        string
+	}{
+		{"utf-8", title, "application/json", "utf-8", title},
+		{"utf-8 bom", "\xef\xbb\xbf" + title, "", "utf-8", title},
+		{"header", latin5, "application/json; charset=ISO-8859-9", "iso-8859-9", title},
+		{"xml prolog", `<?xml version="1.0" encoding="windows-1254"?>` + cp1254, "text/xml", "windows-1254", `<?xml version="1.0" encoding="windows-1254"?>` + title + " “alıntı”"},
+		{"html meta", `<meta charset="iso-8859-9"><p>` + latin5, "text/html", "iso-8859-9", `<meta charset="iso-8859-9"><p>` + title},
+		{"sniff latin5", latin5, "", "iso-8859-9", title},
+		{"sniff windows-1254", cp1254, "", "windows-1254", title + " “alıntı”"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, charset, err := DecodeBody([]byte(tt.body), tt.contentType)
+			if err != nil {
+				t.Fatalf("DecodeBody returned error: %v", err)
+			}
+			if charset != tt.wantCharset {
+				t.Errorf("charset = %q, want %q", charset, tt.wantCharset)
+			}
+			if string(got) != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasEncodingDamage(t *testing.T) {
+	if hasEncodingDamage("Güneş İzmir'de doğdu") {
+		t.Error("Valid Turkish text reported as damaged")
+	}
+	for _, damaged := range []string{"GÃ¼neÅŸ", "Ä°zmir", "bozuk � karakter", "geçersiz \xff bayt"} {
+		if !hasEncodingDamage(damaged) {
+			t.Errorf("Expected %q to be reported as damaged", damaged)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package feed
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// positiveHints and negativeHints match class and id attributes that
+	// usually mark article bodies and page chrome respectively. Negative
+	// hints must be whole tokens of the attribute, split at spaces, "_" and
+	// "-", optionally in plural, so "nav" does not match "canvas" and "ads"
+	// does not match "downloads".
+	positiveHints = regexp.MustCompile(`(?i)article|body|content|entry|haber|detay|icerik|main|news|post|story|text`)
+	negativeHints = regexp.MustCompile(`(?i)(^|[\s_-])(ad|advert|advertisement|banner|breadcrumb|comment|cookie|footer|header|menu|nav|newsletter|paylas|popup|promo|related|reklam|share|sidebar|social|sponsor|tag|widget|yorum)(s|lar|ler)?([\s_-]|$)`)
+)
+
+// skipTags never contain article text
+var skipTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Nav: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Form: true,
+	atom.Iframe: true, atom.Svg: true, atom.Button: true, atom.Select: true,
+}
+
+// Article is the main content and metadata extracted from an article page
+type Article struct {
+	Title       string
+	Description string
+	Content     string
+	Image       string
+	Canonical   string
+	// Metadata holds og:, twitter: and article: meta properties by name
+	Metadata map[string]string
+}
+
+// Extractor pulls the main content out of article pages, readability style
+type Extractor struct {
+	minParagraphLength int
+}
+
+func NewExtractor() *Extractor {
+	return &Extractor{minParagraphLength: 25}
+}
+
+// Extract parses an article page and returns its main content, lead image and metadata
+func (e *Extractor) Extract(pageURL string, body io.Reader) (*Article, error) {
+	doc, err := html.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse article page: %w", err)
+	}
+
+	article := &Article{Metadata: make(map[string]string)}
+	e.collectMetadata(doc, article)
+
+	root := e.findContentRoot(doc)
+	if root != nil {
+		article.Content = strings.Join(e.paragraphs(root), "\n\n")
+		if article.Image == "" {
+			article.Image = firstImage(root)
+		}
+	}
+
+	article.Image = resolveURL(pageURL, article.Image)
+	article.Canonical = resolveURL(pageURL, article.Canonical)
+
+	if article.Content == "" {
+		return article, fmt.Errorf("no article content found in %s", pageURL)
+	}
+	return article, nil
+}
+
+// collectMetadata reads title, description, image and canonical link from the document head
+func (e *Extractor) collectMetadata(doc *html.Node, article *Article) {
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Body:
+			return false
+		case atom.Title:
+			if article.Title == "" {
+				article.Title = strings.TrimSpace(textContent(n))
+			}
+		case atom.Link:
+			if strings.EqualFold(attr(n, "rel"), "canonical") {
+				article.Canonical = attr(n, "href")
+			}
+		case atom.Meta:
+			name := strings.ToLower(attr(n, "property"))
+			if name == "" {
+				name = strings.ToLower(attr(n, "name"))
+			}
+			content := strings.TrimSpace(attr(n, "content"))
+			if content == "" {
+				return true
+			}
+			if strings.HasPrefix(name, "og:") || strings.HasPrefix(name, "twitter:") || strings.HasPrefix(name, "article:") {
+				if _, exists := article.Metadata[name]; !exists {
+					article.Metadata[name] = content
+				}
+			}
+			if name == "description" && article.Description == "" {
+				article.Description = content
+			}
+		}
+		return true
+	})
+
+	meta := article.Metadata
+	article.Title = firstNonEmpty(meta["og:title"], meta["twitter:title"], article.Title)
+	article.Description = firstNonEmpty(meta["og:description"], meta["twitter:description"], article.Description)
+	article.Image = firstNonEmpty(meta["og:image"], meta["og:image:url"], meta["twitter:image"], meta["twitter:image:src"])
+	if article.Canonical == "" {
+		article.Canonical = meta["og:url"]
+	}
+}
+
+// findContentRoot scores the parents of paragraphs and returns the best candidate
+func (e *Extractor) findContentRoot(doc *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && (skipTags[n.DataAtom] || isUnlikely(n)) {
+			return false
+		}
+		if n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Blockquote {
+			return true
+		}
+
+		text := strings.TrimSpace(textContent(n))
+		if len([]rune(text)) < e.minParagraphLength {
+			return false
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + float64(min(len([]rune(text))/100, 3))
+		for depth, parent := 0, n.Parent; parent != nil && depth < 3; depth, parent = depth+1, parent.Parent {
+			if parent.Type != html.ElementNode {
+				break
+			}
+			if _, seen := scores[parent]; !seen {
+				scores[parent] = classWeight(parent)
+				candidates = append(candidates, parent)
+			}
+			scores[parent] += score / float64(depth+1)
+		}
+		return false
+	})
+
+	var best *html.Node
+	for _, c := range candidates {
+		// Penalise candidates that are mostly links, such as lists of related stories
+		score := scores[c] * (1 - linkDensity(c))
+		if best == nil || score > scores[best]*(1-linkDensity(best)) {
+			best = c
+		}
+	}
+	return best
+}
+
+// paragraphs returns the text blocks of the content root in document order
+func (e *Extractor) paragraphs(root *html.Node) []string {
+	var blocks []string
+	walk(root, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && (skipTags[n.DataAtom] || isUnlikely(n)) {
+			return false
+		}
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Blockquote, atom.H2, atom.H3, atom.H4, atom.Li:
+			text := strings.Join(strings.Fields(textContent(n)), " ")
+			if text != "" && (n.DataAtom != atom.P || len([]rune(text)) >= e.minParagraphLength) {
+				blocks = append(blocks, text)
+			}
+			return false
+		}
+		return true
+	})
+	return blocks
+}
+
+// walk visits n and its descendants depth first; fn returns false to skip children
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && skipTags[c.DataAtom] {
+			return false
+		}
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return true
+	})
+	return b.String()
+}
+
+func linkDensity(n *html.Node) float64 {
+	total := len(strings.TrimSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			linked += len(strings.TrimSpace(textContent(c)))
+			return false
+		}
+		return true
+	})
+	return float64(linked) / float64(total)
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, hint := range []string{attr(n, "class"), attr(n, "id")} {
+		if hint == "" {
+			continue
+		}
+		if negativeHints.MatchString(hint) {
+			weight -= 25
+		}
+		if positiveHints.MatchString(hint) {
+			weight += 25
+		}
+	}
+	if n.DataAtom == atom.Article {
+		weight += 25
+	}
+	return weight
+}
+
+// isUnlikely reports whether an element is page chrome rather than content
+func isUnlikely(n *html.Node) bool {
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Article {
+		return false
+	}
+	hint := attr(n, "class") + " " + attr(n, "id")
+	return negativeHints.MatchString(hint) && !positiveHints.MatchString(hint)
+}
+
+func firstImage(root *html.Node) string {
+	var src string
+	walk(root, func(n *html.Node) bool {
+		if src != "" {
+			return false
+		}
+		if n.DataAtom == atom.Img {
+			src = firstNonEmpty(attr(n, "data-src"), attr(n, "src"))
+			return false
+		}
+		return true
+	})
+	return src
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resolveURL makes ref absolute relative to base
+func resolveURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := b.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return r.String()
+}
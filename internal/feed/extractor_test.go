@@ -0,0 +1,100 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func extractFixture(t *testing.T, name, pageURL string) *Article {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	article, err := NewExtractor().Extract(pageURL, f)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	return article
+}
+
+func TestExtractOpenGraphArticle(t *testing.T) {
+	article := extractFixture(t, "article_og.html", "https://www.ornekhaber.com/ekonomi/merkez-bankasi?utm_source=rss")
+
+	paragraphs := strings.Split(article.Content, "\n\n")
+	if len(paragraphs) != 3 {
+		t.Fatalf("Expected 3 paragraphs, got %d: %q", len(paragraphs), article.Content)
+	}
+	if !strings.HasPrefix(paragraphs[0], "Türkiye Cumhuriyet Merkez Bankası") {
+		t.Errorf("Unexpected first paragraph: %q", paragraphs[0])
+	}
+	for _, unwanted := range []string{"Sponsorlu", "Okur yorumu", "Dolar kuru", "Tüm hakları", "dataLayer", "Paylaş"} {
+		if strings.Contains(article.Content, unwanted) {
+			t.Errorf("Content should not contain %q: %q", unwanted, article.Content)
+		}
+	}
+
+	if article.Title != "Merkez Bankası faiz kararını açıkladı" {
+		t.Errorf("Unexpected title: %q", article.Title)
+	}
+	if article.Description != "Merkez Bankası politika faizini yüzde 50'de sabit tuttu." {
+		t.Errorf("Unexpected description: %q", article.Description)
+	}
+	if article.Image != "https://www.ornekhaber.com/images/merkez-bankasi.jpg" {
+		t.Errorf("Expected og:image resolved against page URL, got %q", article.Image)
+	}
+	if article.Canonical != "https://www.ornekhaber.com/ekonomi/merkez-bankasi-faiz-karari" {
+		t.Errorf("Unexpected canonical: %q", article.Canonical)
+	}
+	if article.Metadata["article:published_time"] != "2025-10-24T10:30:00+03:00" {
+		t.Errorf("Expected article metadata, got %v", article.Metadata)
+	}
+}
+
+func TestExtractTwitterArticle(t *testing.T) {
+	article := extractFixture(t, "article_twitter.html", "https://spor.example.com/haber/1")
+
+	if !strings.Contains(article.Content, "Fenerbahçe, Süper Lig'in 10. haftasında") {
+		t.Errorf("Expected article body in content, got %q", article.Content)
+	}
+	if !strings.Contains(article.Content, "Takımım karakter gösterdi") {
+		t.Errorf("Expected blockquote in content, got %q", article.Content)
+	}
+	if strings.Contains(article.Content, "Anasayfa") || strings.Contains(article.Content, "İletişim") {
+		t.Errorf("Content should not contain navigation: %q", article.Content)
+	}
+	if article.Title != "Fenerbahçe deplasmanda 3 puanı aldı" {
+		t.Errorf("Expected twitter:title, got %q", article.Title)
+	}
+	if article.Image != "https://cdn.example.com/spor/fb.jpg" {
+		t.Errorf("Expected twitter:image, got %q", article.Image)
+	}
+}
+
+func TestNegativeHintsMatchWholeTokens(t *testing.T) {
+	cases := map[string]bool{
+		"main-nav":       true,
+		"nav":            true,
+		"site_footer":    true,
+		"ad-slot":        true,
+		"ads":            true,
+		"share-buttons":  true,
+		"yorumlar":       true,
+		"post-tags":      true,
+		"comments":       true,
+		"canvas":         false,
+		"downloads":      false,
+		"subheader":      false,
+		"thread":         false,
+		"shared-content": false,
+	}
+	for hint, want := range cases {
+		if got := negativeHints.MatchString(hint); got != want {
+			t.Errorf("Expected negativeHints to match %q: %v, got %v", hint, want, got)
+		}
+	}
+}
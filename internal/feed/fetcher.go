@@ -2,85 +2,51 @@ package feed
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/bilgisen/goen/internal/config"
 	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
 	"github.com/go-resty/resty/v2"
 )
 
 type Fetcher struct {
-	client *resty.Client
+	client        *resty.Client
+	robots        *robotsCache
+	maxConcurrent int
+	sources       *config.Sources
+	health        *HealthTracker
+
+	hooksMu sync.Mutex
+	hooks   map[string][]PreFetchHook
 }
 
-// isRenderService checks if the URL is hosted on Render.com
-func isRenderService(feedURL string) bool {
-	parsedURL, err := url.Parse(feedURL)
-	if err != nil {
-		return false
+func NewFetcher(cfg *config.Config) *Fetcher {
+	client := resty.New().
+		SetTimeout(cfg.FeedRequestTimeout).
+		SetRetryCount(cfg.FeedMaxRetries).
+		SetRetryWaitTime(2 * time.Second).
+		SetRetryMaxWaitTime(10 * time.Second).
+		SetHeader("User-Agent", cfg.FeedUserAgent).
+		SetResponseBodyLimit(int(cfg.FeedMaxResponseSize))
+
+	maxConcurrent := cfg.FeedMaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
 	}
-	return strings.Contains(parsedURL.Host, "onrender.com")
-}
-
-// wakeUpRenderService sends a simple request to wake up a Render service
-func (f *Fetcher) wakeUpRenderService(ctx context.Context, feedURL string) error {
-	if !isRenderService(feedURL) {
-		return nil // Not a Render service, skip
-	}
-
-	logger.Get().Info().
-		Str("url", feedURL).
-		Msg("Detected Render service, sending wake up ping")
-
-	parsedURL, err := url.Parse(feedURL)
-	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	// Create a simple ping URL (remove query parameters for ping)
-	pingURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
 
-	logger.Get().Debug().
-		Str("ping_url", pingURL).
-		Msg("Sending HEAD request to wake up Render service")
-
-	// Send a quick HEAD request to wake up the service
-	resp, err := f.client.R().
-		SetContext(ctx).
-		SetTimeout(10 * time.Second). // Shorter timeout for ping
-		Head(pingURL)
-
-	if err != nil {
-		return fmt.Errorf("failed to ping Render service %s: %w", pingURL, err)
-	}
-
-	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
-		return fmt.Errorf("Render service %s returned status code %d", pingURL, resp.StatusCode())
-	}
-
-	logger.Get().Info().
-		Str("service", pingURL).
-		Int("status_code", resp.StatusCode()).
-		Msg("Successfully woke up Render service")
-
-	// Wait a bit for the service to fully wake up
-	time.Sleep(2 * time.Second)
-
-	return nil
-}
-
-func NewFetcher() *Fetcher {
+	limiter := newHostLimiter(cfg.FeedHostInterval)
+	limiter.spaceRequests(client)
 	return &Fetcher{
-		client: resty.New().
-			SetTimeout(30 * time.Second).
-			SetRetryCount(3).
-			SetRetryWaitTime(2 * time.Second).
-			SetRetryMaxWaitTime(10 * time.Second),
+		client:        client,
+		robots:        newRobotsCache(client, limiter, cfg.FeedUserAgent),
+		maxConcurrent: maxConcurrent,
+		sources:       cfg.Sources,
+		health:        NewHealthTracker(cfg.FeedHealthPath, cfg.FeedBackoffBase, cfg.FeedBackoffMax, cfg.FeedDisableAfter),
+		hooks:         make(map[string][]PreFetchHook),
 	}
 }
 
@@ -96,6 +62,7 @@ type JSONFeed struct {
 		Description string `json:"description"`
 		Content     string `json:"content"`
 		Image       string `json:"image"`
+		Category    string `json:"category"`
 	} `json:"items"`
 	ItemsReturned int `json:"items_returned"`
 	ItemsSkipped  int `json:"items_skipped"`
@@ -103,98 +70,180 @@ type JSONFeed struct {
 
 // FetchFeed retrieves a feed from the given URL and parses it into FeedItems
 func (f *Fetcher) FetchFeed(ctx context.Context, url string) ([]models.FeedItem, error) {
-	// First, wake up Render services if needed
-	if err := f.wakeUpRenderService(ctx, url); err != nil {
-		// Log the error but don't fail the entire operation
-		// The main fetch might still work even if ping fails
-		logger.Get().Warn().
-			Err(err).
+	req := f.client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/json, application/rss+xml, application/atom+xml;q=0.9, */*;q=0.8")
+
+	// Run the source's pre-fetch hooks, e.g. waking up a sleeping service.
+	// The client spaces their requests and the fetch itself per host.
+	f.runHooks(ctx, req, url)
+
+	resp, err := req.Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed from %s: %w", url, err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode(), url)
+	}
+
+	// Transcode legacy Turkish encodings to UTF-8 before parsing
+	body, charset, err := DecodeBody(resp.Body(), resp.Header().Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode feed from %s: %w", url, err)
+	}
+	if charset != "utf-8" {
+		logger.Get().Debug().
 			Str("url", url).
-			Msg("Failed to wake up Render service, continuing with fetch")
+			Str("charset", charset).
+			Msg("Transcoded feed to UTF-8")
+	}
+
+	return ParseFeed(body, url)
+}
+
+// FetchPage retrieves an article page and returns its body together with
+// the final URL after redirects. Pages disallowed by robots.txt are not fetched.
+func (f *Fetcher) FetchPage(ctx context.Context, pageURL string) ([]byte, string, error) {
+	if !f.robots.Allowed(ctx, pageURL) {
+		return nil, "", fmt.Errorf("fetching %s is disallowed by robots.txt", pageURL)
 	}
 
 	resp, err := f.client.R().
 		SetContext(ctx).
-		SetHeader("Accept", "application/json").
-		Get(url)
+		SetHeader("Accept", "text/html,application/xhtml+xml").
+		Get(pageURL)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch feed from %s: %w", url, err)
+		return nil, "", fmt.Errorf("failed to fetch page %s: %w", pageURL, err)
 	}
 
 	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode(), url)
+		return nil, "", fmt.Errorf("unexpected status code %d from %s", resp.StatusCode(), pageURL)
 	}
 
-	// Try to parse as JSON feed structure first
-	var jsonFeed JSONFeed
-	if err := json.Unmarshal(resp.Body(), &jsonFeed); err == nil && len(jsonFeed.Items) > 0 {
-		// Successfully parsed as JSON feed, convert to our model
-		items := make([]models.FeedItem, 0, len(jsonFeed.Items))
-		for _, item := range jsonFeed.Items {
-			// Use link as fallback if guid is empty
-			guid := item.Guid
-			if guid == "" {
-				guid = item.Link
-			}
-
-			items = append(items, models.FeedItem{
-				Guid:      guid,
-				TitleTR:   item.Title,
-				ContentTR: item.Content,
-				Image:     item.Image,
-				Url:       item.Link,
-				Category:  "general", // Default category
-			})
-		}
-		return items, nil
+	finalURL := pageURL
+	if resp.RawResponse != nil && resp.RawResponse.Request != nil {
+		finalURL = resp.RawResponse.Request.URL.String()
 	}
 
-	// Fallback to the original parsing logic for other formats
-	var items []models.FeedItem
-	if err := json.Unmarshal(resp.Body(), &items); err != nil {
-		// If it's not an array, try to parse as a single item
-		var singleItem models.FeedItem
-		if singleErr := json.Unmarshal(resp.Body(), &singleItem); singleErr != nil {
-			return nil, fmt.Errorf("failed to parse feed response: %w (tried both JSON feed and array formats)", err)
-		}
-		items = []models.FeedItem{singleItem}
+	body, _, err := DecodeBody(resp.Body(), resp.Header().Get("Content-Type"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode page %s: %w", pageURL, err)
 	}
 
-	return items, nil
+	return body, finalURL, nil
+}
+
+// Health returns the per-feed health tracker
+func (f *Fetcher) Health() *HealthTracker {
+	return f.health
+}
+
+// FeedResult is the outcome of fetching a single feed
+type FeedResult struct {
+	URL      string
+	Items    []models.FeedItem
+	Err      error
+	Skipped  string // reason the feed was not fetched, e.g. backoff
+	Duration time.Duration
 }
 
-// FetchMultipleFeeds concurrently fetches multiple feeds
-func (f *Fetcher) FetchMultipleFeeds(ctx context.Context, urls []string) ([]models.FeedItem, error) {
-	type result struct {
-		items []models.FeedItem
-		err   error
+// Status summarizes the result for job records
+func (r FeedResult) Status() models.FeedStatus {
+	status := models.FeedStatus{
+		URL:        r.URL,
+		Status:     models.FeedStatusOK,
+		Items:      len(r.Items),
+		DurationMs: r.Duration.Milliseconds(),
+	}
+	switch {
+	case r.Err != nil:
+		status.Status = models.FeedStatusFailed
+		status.Error = r.Err.Error()
+	case r.Skipped != "":
+		status.Status = models.FeedStatusSkipped
+		status.Error = r.Skipped
 	}
+	return status
+}
+
+// FetchResults lists the result of every requested feed in request order
+type FetchResults []FeedResult
 
-	results := make(chan result, len(urls))
+// Items returns the items of all feeds that were fetched successfully
+func (r FetchResults) Items() []models.FeedItem {
+	var items []models.FeedItem
+	for _, res := range r {
+		items = append(items, res.Items...)
+	}
+	return items
+}
 
-	for _, url := range urls {
-		go func(u string) {
-			items, err := f.FetchFeed(ctx, u)
-			results <- result{items: items, err: err}
-		}(url)
+// Failed returns the results of the feeds that failed
+func (r FetchResults) Failed() FetchResults {
+	var failed FetchResults
+	for _, res := range r {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
 	}
+	return failed
+}
 
-	var allItems []models.FeedItem
-	var errs []error
+// Statuses summarizes all results for job records
+func (r FetchResults) Statuses() []models.FeedStatus {
+	statuses := make([]models.FeedStatus, len(r))
+	for i, res := range r {
+		statuses[i] = res.Status()
+	}
+	return statuses
+}
 
-	for i := 0; i < len(urls); i++ {
-		res := <-results
-		if res.err != nil {
-			errs = append(errs, res.err)
+// FetchMultipleFeeds concurrently fetches multiple feeds, at most
+// maxConcurrent at a time, and reports the outcome of each one. Feeds that
+// are backing off or disabled are skipped.
+func (f *Fetcher) FetchMultipleFeeds(ctx context.Context, urls []string) FetchResults {
+	results := make(FetchResults, len(urls))
+	semaphore := make(chan struct{}, f.maxConcurrent)
+	var wg sync.WaitGroup
+
+	for i, url := range urls {
+		results[i].URL = url
+		if ok, reason := f.health.Allow(url); !ok {
+			logger.Get().Info().
+				Str("url", url).
+				Str("reason", reason).
+				Msg("Skipping unhealthy feed")
+			results[i].Skipped = reason
 			continue
 		}
-		allItems = append(allItems, res.items...)
-	}
 
-	if len(errs) > 0 {
-		return allItems, fmt.Errorf("encountered %d errors while fetching feeds, first error: %v", len(errs), errs[0])
+		wg.Add(1)
+		go func(res *FeedResult) {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				res.Err = ctx.Err()
+				return
+			case semaphore <- struct{}{}:
+			}
+			defer func() { <-semaphore }()
+
+			start := time.Now()
+			res.Items, res.Err = f.FetchFeed(ctx, res.URL)
+			res.Duration = time.Since(start)
+			switch {
+			case res.Err == nil:
+				f.health.RecordSuccess(res.URL, res.Duration, len(res.Items))
+			case ctx.Err() == nil:
+				// Cancelled runs say nothing about the feed itself
+				f.health.RecordFailure(res.URL, res.Duration, res.Err)
+			}
+		}(&results[i])
 	}
 
-	return allItems, nil
+	wg.Wait()
+	return results
 }
@@ -0,0 +1,95 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func TestFetchMultipleFeedsPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"guid":"1","title":"Başlık","content":"İçerik","url":"https://example.com/1"}]`))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(&config.Config{
+		FeedMaxConcurrent: 2,
+		FeedBackoffBase:   time.Minute,
+		FeedBackoffMax:    time.Hour,
+	})
+	urls := []string{server.URL + "/ok", server.URL + "/broken"}
+
+	results := f.FetchMultipleFeeds(context.Background(), urls)
+	if len(results) != 2 || results[0].URL != urls[0] || results[1].URL != urls[1] {
+		t.Fatalf("Expected one result per URL in request order, got %+v", results)
+	}
+	if items := results.Items(); len(items) != 1 || items[0].Source != urls[0] {
+		t.Errorf("Expected the item of the healthy feed, got %+v", items)
+	}
+	failed := results.Failed()
+	if len(failed) != 1 || failed[0].URL != urls[1] || failed[0].Err == nil {
+		t.Fatalf("Expected the broken feed to fail, got %+v", failed)
+	}
+	if status := failed[0].Status(); status.Status != models.FeedStatusFailed || status.Error == "" {
+		t.Errorf("Expected a failed status with the error, got %+v", status)
+	}
+
+	// The broken feed now backs off and is skipped on the next run
+	results = f.FetchMultipleFeeds(context.Background(), urls)
+	if results[1].Skipped == "" || results[1].Err != nil {
+		t.Errorf("Expected the broken feed to be skipped, got %+v", results[1])
+	}
+	if status := results[1].Status(); status.Status != models.FeedStatusSkipped {
+		t.Errorf("Expected a skipped status, got %+v", status)
+	}
+	if len(results.Items()) != 1 {
+		t.Errorf("Expected the healthy feed to be fetched again")
+	}
+}
+
+func TestFetchFeedSpacesRetries(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		first := len(times) == 1
+		mu.Unlock()
+		if first {
+			// Drop the connection, so the client retries
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"guid":"1","title":"Başlık","content":"İçerik","url":"https://example.com/1"}]`))
+	}))
+	defer server.Close()
+
+	interval := 200 * time.Millisecond
+	f := NewFetcher(&config.Config{FeedMaxRetries: 1, FeedHostInterval: interval})
+	f.client.SetRetryWaitTime(time.Millisecond).SetRetryMaxWaitTime(time.Millisecond)
+
+	if _, err := f.FetchFeed(context.Background(), server.URL+"/feed"); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < interval-10*time.Millisecond {
+		t.Errorf("Expected the retry to wait for the host interval of %v, got %v", interval, gap)
+	}
+}
@@ -0,0 +1,220 @@
+package feed
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// Reasons an item is rejected by the source filters
+const (
+	RejectIncludeKeyword = "include_keyword"
+	RejectExcludeKeyword = "exclude_keyword"
+	RejectIncludePattern = "include_pattern"
+	RejectExcludePattern = "exclude_pattern"
+	RejectCategory       = "category"
+	RejectMinLength      = "min_length"
+	RejectMaxAge         = "max_age"
+	RejectTitle          = "title_blacklist"
+)
+
+// itemFilter applies the filter rules of one source
+type itemFilter struct {
+	includeKeywords []string
+	excludeKeywords []string
+	includePatterns []*regexp.Regexp
+	excludePatterns []*regexp.Regexp
+	allowCategories map[string]bool
+	denyCategories  map[string]bool
+	minLength       int
+	maxAge          time.Duration
+	titleBlacklist  []string
+}
+
+// newItemFilter compiles the rules; patterns were validated when the sources were loaded
+func newItemFilter(cfg config.FilterConfig) *itemFilter {
+	f := &itemFilter{
+		includeKeywords: foldAll(cfg.IncludeKeywords),
+		excludeKeywords: foldAll(cfg.ExcludeKeywords),
+		allowCategories: foldSet(cfg.AllowCategories),
+		denyCategories:  foldSet(cfg.DenyCategories),
+		minLength:       cfg.MinLength,
+		maxAge:          time.Duration(cfg.MaxAge),
+		titleBlacklist:  foldAll(cfg.TitleBlacklist),
+	}
+	for _, pattern := range cfg.IncludePatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			f.includePatterns = append(f.includePatterns, re)
+		}
+	}
+	for _, pattern := range cfg.ExcludePatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			f.excludePatterns = append(f.excludePatterns, re)
+		}
+	}
+	return f
+}
+
+// Check returns the reason the item is rejected, or "" if it passes
+func (f *itemFilter) Check(item models.FeedItem, now time.Time) string {
+	title := fold(item.TitleTR)
+	for _, phrase := range f.titleBlacklist {
+		if strings.Contains(title, phrase) {
+			return RejectTitle
+		}
+	}
+
+	category := fold(strings.TrimSpace(item.Category))
+	if f.denyCategories[category] || (len(f.allowCategories) > 0 && !f.allowCategories[category]) {
+		return RejectCategory
+	}
+
+	if f.maxAge > 0 && !item.PublishedAt.IsZero() && now.Sub(item.PublishedAt) > f.maxAge {
+		return RejectMaxAge
+	}
+	if f.minLength > 0 && utf8.RuneCountInString(item.ContentTR) < f.minLength {
+		return RejectMinLength
+	}
+
+	text := item.TitleTR + "\n" + item.ContentTR
+	folded := fold(text)
+	if len(f.includeKeywords) > 0 && !containsAny(folded, f.includeKeywords) {
+		return RejectIncludeKeyword
+	}
+	if containsAny(folded, f.excludeKeywords) {
+		return RejectExcludeKeyword
+	}
+	if len(f.includePatterns) > 0 && !matchesAny(text, f.includePatterns) {
+		return RejectIncludePattern
+	}
+	if matchesAny(text, f.excludePatterns) {
+		return RejectExcludePattern
+	}
+	return ""
+}
+
+// filterFor returns the compiled filter of the item's source, or nil if it has none
+func (p *Parser) filterFor(source string) *itemFilter {
+	src := p.sources.Get(source)
+	if src.Filters == nil {
+		return nil
+	}
+	key := src.URL
+	if !p.sources.Has(source) {
+		// Unconfigured feeds share the default filters
+		key = ""
+	}
+
+	p.filtersMu.Lock()
+	defer p.filtersMu.Unlock()
+
+	f, ok := p.filters[key]
+	if !ok {
+		f = newItemFilter(*src.Filters)
+		p.filters[key] = f
+	}
+	return f
+}
+
+// rejectionCounter counts filter rejections per source and reason
+type rejectionCounter struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int
+}
+
+func newRejectionCounter() *rejectionCounter {
+	return &rejectionCounter{counts: make(map[string]map[string]int)}
+}
+
+func (r *rejectionCounter) add(source, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts[source] == nil {
+		r.counts[source] = make(map[string]int)
+	}
+	r.counts[source][reason]++
+}
+
+// Snapshot returns a copy of the counts, keyed by source and reason
+func (r *rejectionCounter) Snapshot() map[string]map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]map[string]int, len(r.counts))
+	for source, reasons := range r.counts {
+		snapshot[source] = make(map[string]int, len(reasons))
+		for reason, n := range reasons {
+			snapshot[source][reason] = n
+		}
+	}
+	return snapshot
+}
+
+// logRejections logs the rejection counts of one run
+func logRejections(counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	event := logger.Get().Info()
+	total := 0
+	for _, reason := range reasons {
+		event = event.Int(reason, counts[reason])
+		total += counts[reason]
+	}
+	event.Int("total_rejected", total).Msg("Filtered out feed items")
+}
+
+// fold lowercases text with Turkish casing rules, so "İ" matches "i" and "I" matches "ı"
+func fold(s string) string {
+	return strings.ToLowerSpecial(unicode.TurkishCase, s)
+}
+
+func foldAll(values []string) []string {
+	folded := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = fold(strings.TrimSpace(v)); v != "" {
+			folded = append(folded, v)
+		}
+	}
+	return folded
+}
+
+func foldSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range foldAll(values) {
+		set[v] = true
+	}
+	return set
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(s string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
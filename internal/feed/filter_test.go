@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func TestItemFilterCheck(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	filter := newItemFilter(config.FilterConfig{
+		IncludeKeywords: []string{"ekonomi", "İHRACAT"},
+		ExcludeKeywords: []string{"sponsorlu"},
+		ExcludePatterns: []string{`(?i)\bkampanya\b`},
+		DenyCategories:  []string{"Magazin"},
+		MinLength:       20,
+		MaxAge:          config.Duration(48 * time.Hour),
+		TitleBlacklist:  []string{"günlük burç"},
+	})
+
+	base := models.FeedItem{
+		TitleTR:     "Ekonomi: ihracat rekor kırdı",
+		ContentTR:   "Mart ayında ihracat beklentilerin üzerinde gerçekleşti.",
+		Category:    "Ekonomi",
+		PublishedAt: now.Add(-time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*models.FeedItem)
+		want   string
+	}{
+		{"passes", func(*models.FeedItem) {}, ""},
+		{"title blacklist with Turkish casing", func(i *models.FeedItem) { i.TitleTR = "GÜNLÜK BURÇ yorumları" }, RejectTitle},
+		{"denied category", func(i *models.FeedItem) { i.Category = "magazin" }, RejectCategory},
+		{"too old", func(i *models.FeedItem) { i.PublishedAt = now.Add(-72 * time.Hour) }, RejectMaxAge},
+		{"no date passes age check", func(i *models.FeedItem) { i.PublishedAt = time.Time{} }, ""},
+		{"too short", func(i *models.FeedItem) { i.ContentTR = "Kısa" }, RejectMinLength},
+		{"missing include keyword", func(i *models.FeedItem) {
+			i.TitleTR = "Hava durumu"
+			i.ContentTR = "Yarın hava yağmurlu ve serin olacak."
+		}, RejectIncludeKeyword},
+		{"dotted capital İ matches", func(i *models.FeedItem) {
+			i.TitleTR = "Hava durumu"
+			i.ContentTR = "Yarın ihracatçılar için toplantı yapılacak."
+		}, ""},
+		{"exclude keyword", func(i *models.FeedItem) { i.ContentTR += " Sponsorlu içerik." }, RejectExcludeKeyword},
+		{"exclude pattern", func(i *models.FeedItem) { i.ContentTR += " Büyük Kampanya başladı." }, RejectExcludePattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := base
+			tt.modify(&item)
+			if got := filter.Check(item, now); got != tt.want {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemFilterAllowAndIncludePatterns(t *testing.T) {
+	filter := newItemFilter(config.FilterConfig{
+		AllowCategories: []string{"Spor", "Dünya"},
+		IncludePatterns: []string{`\d+-\d+`},
+	})
+	now := time.Now()
+
+	if got := filter.Check(models.FeedItem{TitleTR: "Derbi 2-1 bitti", Category: "SPOR"}, now); got != "" {
+		t.Errorf("Expected allowed category and matching pattern to pass, got %q", got)
+	}
+	if got := filter.Check(models.FeedItem{TitleTR: "Derbi 2-1 bitti", Category: "Ekonomi"}, now); got != RejectCategory {
+		t.Errorf("Expected category outside the allow list to be rejected, got %q", got)
+	}
+	if got := filter.Check(models.FeedItem{TitleTR: "Derbi bugün", Category: "Spor"}, now); got != RejectIncludePattern {
+		t.Errorf("Expected missing include pattern to be rejected, got %q", got)
+	}
+}
+
+func TestProcessFeedItemsFilters(t *testing.T) {
+	sources, err := config.NewSources(
+		config.Source{Filters: &config.FilterConfig{TitleBlacklist: []string{"burç"}}},
+		config.Source{URL: "https://feeds.example.com/spor", Filters: &config.FilterConfig{AllowCategories: []string{"spor"}}},
+	)
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	parser := NewParser(&config.Config{Sources: sources})
+
+	items := []models.FeedItem{
+		{Guid: "1", TitleTR: "Günün burç yorumu", Url: "https://example.com/1", Source: "https://feeds.example.com/genel"},
+		{Guid: "2", TitleTR: "Seçim sonuçları", Url: "https://example.com/2", Source: "https://feeds.example.com/genel"},
+		{Guid: "3", TitleTR: "Derbi sonucu", Category: "Spor", Url: "https://example.com/3", Source: "https://feeds.example.com/spor"},
+		{Guid: "4", TitleTR: "Dolar yükseldi", Category: "Ekonomi", Url: "https://example.com/4", Source: "https://feeds.example.com/spor"},
+		// The source's own filters replace the defaults, so the blacklist does not apply
+		{Guid: "5", TitleTR: "Burç maçı", Category: "Spor", Url: "https://example.com/5", Source: "https://feeds.example.com/spor"},
+	}
+
+	valid, errs := parser.ProcessFeedItems(context.Background(), items)
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected validation errors: %v", errs)
+	}
+	var guids []string
+	for _, item := range valid {
+		guids = append(guids, item.Guid)
+	}
+	if len(valid) != 3 {
+		t.Errorf("Expected items 2, 3 and 5 to pass, got %s", strings.Join(guids, ","))
+	}
+
+	rejections := parser.Rejections()
+	if rejections["https://feeds.example.com/genel"][RejectTitle] != 1 {
+		t.Errorf("Expected one title rejection for the default source, got %v", rejections)
+	}
+	if rejections["https://feeds.example.com/spor"][RejectCategory] != 1 {
+		t.Errorf("Expected one category rejection for the sports source, got %v", rejections)
+	}
+}
@@ -0,0 +1,290 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// ParseFeed parses a UTF-8 feed body into FeedItems. JSON Feed, our JSON
+// feed format, FeedItem arrays or objects, RSS 2.0 and Atom are supported;
+// source is recorded on every item.
+func ParseFeed(body []byte, source string) ([]models.FeedItem, error) {
+	trimmed := bytes.TrimLeft(body, " \t\r\n\ufeff")
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		items, err := parseXMLFeed(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i].Source = source
+		}
+		return items, nil
+	}
+
+	// JSON Feed (jsonfeed.org) documents carry a version URL
+	var spec jsonFeedSpec
+	if err := json.Unmarshal(body, &spec); err == nil && strings.HasPrefix(spec.Version, "https://jsonfeed.org/version/") {
+		items := make([]models.FeedItem, 0, len(spec.Items))
+		for _, it := range spec.Items {
+			item := models.FeedItem{
+				Url:         firstNonEmpty(it.URL, it.ExternalURL),
+				TitleTR:     strings.TrimSpace(it.Title),
+				ContentTR:   firstNonEmpty(it.ContentHTML, it.ContentText, it.Summary),
+				Image:       firstNonEmpty(it.Image, it.BannerImage),
+				Source:      source,
+				PublishedAt: parseDate(it.Published),
+			}
+			item.Guid = firstNonEmpty(it.ID, item.Url)
+			if len(it.Tags) > 0 && strings.TrimSpace(it.Tags[0]) != "" {
+				item.Category = strings.TrimSpace(it.Tags[0])
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	// Try to parse as JSON feed structure first
+	var jsonFeed JSONFeed
+	if err := json.Unmarshal(body, &jsonFeed); err == nil && len(jsonFeed.Items) > 0 {
+		// Successfully parsed as JSON feed, convert to our model
+		items := make([]models.FeedItem, 0, len(jsonFeed.Items))
+		for _, item := range jsonFeed.Items {
+			// Use link as fallback if guid is empty
+			guid := item.Guid
+			if guid == "" {
+				guid = item.Link
+			}
+
+			items = append(items, models.FeedItem{
+				Guid:        guid,
+				TitleTR:     item.Title,
+				ContentTR:   item.Content,
+				Image:       item.Image,
+				Url:         item.Link,
+				Category:    strings.TrimSpace(item.Category),
+				Source:      source,
+				PublishedAt: parseDate(item.Published),
+			})
+		}
+		return items, nil
+	}
+
+	// Fallback to the original parsing logic for other formats
+	var items []models.FeedItem
+	if err := json.Unmarshal(body, &items); err != nil {
+		// If it's not an array, try to parse as a single item
+		var singleItem models.FeedItem
+		if singleErr := json.Unmarshal(body, &singleItem); singleErr != nil {
+			return nil, fmt.Errorf("failed to parse feed response: %w (tried both JSON feed and array formats)", err)
+		}
+		items = []models.FeedItem{singleItem}
+	}
+
+	for i := range items {
+		items[i].Source = source
+	}
+
+	return items, nil
+}
+
+// jsonFeedSpec is the subset of JSON Feed 1.x we read
+type jsonFeedSpec struct {
+	Version string `json:"version"`
+	Items   []struct {
+		ID          string   `json:"id"`
+		URL         string   `json:"url"`
+		ExternalURL string   `json:"external_url"`
+		Title       string   `json:"title"`
+		ContentHTML string   `json:"content_html"`
+		ContentText string   `json:"content_text"`
+		Summary     string   `json:"summary"`
+		Image       string   `json:"image"`
+		BannerImage string   `json:"banner_image"`
+		Tags        []string `json:"tags"`
+		Published   string   `json:"date_published"`
+	} `json:"items"`
+}
+
+// rssFeed is the subset of RSS 2.0 we read
+type rssFeed struct {
+	Items []struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		Guid        string   `xml:"guid"`
+		Description string   `xml:"description"`
+		Encoded     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		PubDate     string   `xml:"pubDate"`
+		Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Categories  []string `xml:"category"`
+		Enclosures  []struct {
+			URL  string `xml:"url,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"enclosure"`
+		Media []struct {
+			URL    string `xml:"url,attr"`
+			Medium string `xml:"medium,attr"`
+			Type   string `xml:"type,attr"`
+		} `xml:"http://search.yahoo.com/mrss/ content"`
+		Thumbnail struct {
+			URL string `xml:"url,attr"`
+		} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	} `xml:"channel>item"`
+}
+
+// atomFeed is the subset of Atom we read
+type atomFeed struct {
+	Entries []struct {
+		ID        string `xml:"id"`
+		Title     string `xml:"title"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+		Links     []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"link"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+// parseXMLFeed parses an RSS 2.0 or Atom document
+func parseXMLFeed(body []byte) ([]models.FeedItem, error) {
+	root, err := xmlRoot(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML feed: %w", err)
+	}
+
+	switch root {
+	case "rss":
+		var feed rssFeed
+		if err := decodeXML(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		items := make([]models.FeedItem, 0, len(feed.Items))
+		for _, it := range feed.Items {
+			item := models.FeedItem{
+				Guid:        firstNonEmpty(it.Guid, it.Link),
+				TitleTR:     strings.TrimSpace(it.Title),
+				ContentTR:   firstNonEmpty(it.Encoded, it.Description),
+				Url:         strings.TrimSpace(it.Link),
+				PublishedAt: parseDate(firstNonEmpty(it.PubDate, it.Date)),
+			}
+			if len(it.Categories) > 0 && strings.TrimSpace(it.Categories[0]) != "" {
+				item.Category = strings.TrimSpace(it.Categories[0])
+			}
+			for _, enc := range it.Enclosures {
+				if item.Image == "" && strings.HasPrefix(enc.Type, "image/") {
+					item.Image = enc.URL
+				}
+			}
+			for _, m := range it.Media {
+				if item.Image == "" && (m.Medium == "image" || strings.HasPrefix(m.Type, "image/")) {
+					item.Image = m.URL
+				}
+			}
+			if item.Image == "" {
+				item.Image = it.Thumbnail.URL
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	case "feed":
+		var feed atomFeed
+		if err := decodeXML(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+		}
+		items := make([]models.FeedItem, 0, len(feed.Entries))
+		for _, e := range feed.Entries {
+			item := models.FeedItem{
+				TitleTR:     strings.TrimSpace(e.Title),
+				ContentTR:   firstNonEmpty(e.Content, e.Summary),
+				PublishedAt: parseDate(firstNonEmpty(e.Published, e.Updated)),
+			}
+			for _, l := range e.Links {
+				switch {
+				case (l.Rel == "" || l.Rel == "alternate") && item.Url == "":
+					item.Url = l.Href
+				case l.Rel == "enclosure" && strings.HasPrefix(l.Type, "image/") && item.Image == "":
+					item.Image = l.Href
+				}
+			}
+			item.Guid = firstNonEmpty(e.ID, item.Url)
+			if len(e.Categories) > 0 && e.Categories[0].Term != "" {
+				item.Category = e.Categories[0].Term
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("unsupported XML feed root element <%s>", root)
+}
+
+// dateLayouts are the date formats seen in feeds, tried in order
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses a feed date, returning the zero time if it is missing or unknown
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// xmlRoot returns the local name of the document element
+func xmlRoot(body []byte) (string, error) {
+	dec := newXMLDecoder(body)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func decodeXML(body []byte, v interface{}) error {
+	return newXMLDecoder(body).Decode(v)
+}
+
+// newXMLDecoder returns a lenient decoder for bodies already transcoded to
+// UTF-8, so the charset of the XML prolog is ignored
+func newXMLDecoder(body []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return dec
+}
@@ -0,0 +1,114 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFeedRSS(t *testing.T) {
+	// The prolog still names the original charset after DecodeBody transcoded the body
+	body, err := os.ReadFile(filepath.Join("testdata", "feed_rss.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	items, err := ParseFeed(body, "https://www.example.com/rss")
+	if err != nil {
+		t.Fatalf("ParseFeed returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.Guid != "haber-1001" || first.TitleTR != "Merkez Bankası faiz kararını açıkladı" {
+		t.Errorf("Unexpected guid or title: %+v", first)
+	}
+	if first.ContentTR != "<p>Merkez Bankası politika faizini sabit tuttu.</p>" {
+		t.Errorf("Expected content:encoded to win over description, got %q", first.ContentTR)
+	}
+	if first.Category != "Ekonomi" || first.Image != "https://img.example.com/faiz.jpg" {
+		t.Errorf("Unexpected category or image: %+v", first)
+	}
+	if want := time.Date(2025, 3, 10, 6, 30, 0, 0, time.UTC); !first.PublishedAt.Equal(want) {
+		t.Errorf("Expected pubDate %v, got %v", want, first.PublishedAt)
+	}
+	if first.Source != "https://www.example.com/rss" {
+		t.Errorf("Expected source to be recorded, got %q", first.Source)
+	}
+
+	second := items[1]
+	if second.Guid != "https://www.example.com/spor/derbi" {
+		t.Errorf("Expected link as guid fallback, got %q", second.Guid)
+	}
+	if second.ContentTR != "Derbi & sonrası" || second.Category != "" {
+		t.Errorf("Unexpected content or category: %+v", second)
+	}
+	if second.Image != "https://img.example.com/derbi.jpg" {
+		t.Errorf("Expected enclosure image, got %q", second.Image)
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "feed_atom.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	items, err := ParseFeed(body, "file://feed_atom.xml")
+	if err != nil {
+		t.Fatalf("ParseFeed returned error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+
+	item := items[0]
+	if item.Guid != "tag:example.com,2025:haber-2001" || item.Url != "https://www.example.com/yasam/metro" {
+		t.Errorf("Unexpected guid or url: %+v", item)
+	}
+	if item.ContentTR != "<p>Hat bugün hizmete girdi.</p>" || item.Category != "Yaşam" {
+		t.Errorf("Unexpected content or category: %+v", item)
+	}
+	if item.Image != "https://img.example.com/metro.png" {
+		t.Errorf("Expected enclosure link as image, got %q", item.Image)
+	}
+}
+
+func TestParseFeedJSON(t *testing.T) {
+	body := []byte(`{"feed_title":"Örnek","items":[{"title":"Başlık","link":"https://example.com/1","content":"İçerik"}]}`)
+
+	items, err := ParseFeed(body, "https://example.com/feed")
+	if err != nil {
+		t.Fatalf("ParseFeed returned error: %v", err)
+	}
+	if len(items) != 1 || items[0].Guid != "https://example.com/1" || items[0].Source != "https://example.com/feed" {
+		t.Errorf("Unexpected items: %+v", items)
+	}
+
+	if _, err := ParseFeed([]byte("<html><body>not a feed</body></html>"), ""); err == nil {
+		t.Error("Expected an error for an unsupported XML document")
+	}
+}
+
+func TestParseFeedJSONFeedSpec(t *testing.T) {
+	body := []byte(`{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "Örnek",
+		"items": [{"id": "a-1", "url": "https://example.com/a", "title": "Başlık", "content_html": "<p>İçerik</p>", "image": "https://img.example.com/a.jpg", "tags": ["Dünya"]}]
+	}`)
+
+	items, err := ParseFeed(body, "https://example.com/feed.json")
+	if err != nil {
+		t.Fatalf("ParseFeed returned error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Guid != "a-1" || item.ContentTR != "<p>İçerik</p>" || item.Category != "Dünya" || item.Image != "https://img.example.com/a.jpg" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+}
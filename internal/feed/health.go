@@ -0,0 +1,225 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/utils"
+)
+
+// FeedHealth is the fetch history of a single feed
+type FeedHealth struct {
+	URL                 string    `json:"url"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	TotalFetches        int       `json:"total_fetches"`
+	TotalFailures       int       `json:"total_failures"`
+	AvgLatencyMs        float64   `json:"avg_latency_ms"`
+	AvgItems            float64   `json:"avg_items_per_fetch"`
+	NextAttempt         time.Time `json:"next_attempt,omitempty"`
+	Disabled            bool      `json:"disabled"`
+	DisabledAt          time.Time `json:"disabled_at,omitempty"`
+}
+
+// HealthTracker records per-feed fetch outcomes, backs off failing feeds
+// exponentially and disables feeds that keep failing. Records are persisted
+// to a JSON file so backoff survives restarts.
+type HealthTracker struct {
+	mu           sync.Mutex
+	path         string
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	disableAfter int
+	records      map[string]*FeedHealth
+}
+
+// NewHealthTracker creates a tracker persisted at path and loads earlier records.
+// An empty path keeps records in memory only.
+func NewHealthTracker(path string, baseBackoff, maxBackoff time.Duration, disableAfter int) *HealthTracker {
+	h := &HealthTracker{
+		path:         path,
+		baseBackoff:  baseBackoff,
+		maxBackoff:   maxBackoff,
+		disableAfter: disableAfter,
+		records:      make(map[string]*FeedHealth),
+	}
+
+	if path != "" {
+		if err := h.load(); err != nil {
+			logger.Get().Warn().
+				Err(err).
+				Str("path", path).
+				Msg("Failed to load feed health records")
+		}
+	}
+	return h
+}
+
+// Allow reports whether a feed may be fetched now, and why not otherwise
+func (h *HealthTracker) Allow(url string) (bool, string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rec, ok := h.records[url]
+	switch {
+	case !ok:
+		return true, ""
+	case rec.Disabled:
+		return false, fmt.Sprintf("disabled after %d consecutive failures", rec.ConsecutiveFailures)
+	case time.Now().Before(rec.NextAttempt):
+		return false, fmt.Sprintf("backing off until %s", rec.NextAttempt.Format(time.RFC3339))
+	}
+	return true, ""
+}
+
+// RecordSuccess records a successful fetch and clears any backoff
+func (h *HealthTracker) RecordSuccess(url string, latency time.Duration, items int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rec := h.record(url)
+	rec.observe(latency)
+	successes := rec.TotalFetches - rec.TotalFailures
+	rec.AvgItems += (float64(items) - rec.AvgItems) / float64(successes)
+	rec.LastSuccess = time.Now()
+	rec.ConsecutiveFailures = 0
+	rec.NextAttempt = time.Time{}
+
+	h.save()
+}
+
+// RecordFailure records a failed fetch, schedules the next attempt with
+// exponential backoff and disables the feed once it reaches the threshold
+func (h *HealthTracker) RecordFailure(url string, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rec := h.record(url)
+	rec.TotalFailures++
+	rec.observe(latency)
+	rec.ConsecutiveFailures++
+	rec.LastFailure = time.Now()
+	rec.LastError = err.Error()
+
+	backoff := h.baseBackoff
+	for i := 1; i < rec.ConsecutiveFailures && backoff < h.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > h.maxBackoff {
+		backoff = h.maxBackoff
+	}
+	rec.NextAttempt = rec.LastFailure.Add(backoff)
+
+	if h.disableAfter > 0 && rec.ConsecutiveFailures >= h.disableAfter && !rec.Disabled {
+		rec.Disabled = true
+		rec.DisabledAt = rec.LastFailure
+		logger.Get().Warn().
+			Str("url", url).
+			Int("consecutive_failures", rec.ConsecutiveFailures).
+			Str("last_error", rec.LastError).
+			Msg("Disabling feed after repeated failures")
+	}
+
+	h.save()
+}
+
+// Enable re-enables a feed and clears its backoff
+func (h *HealthTracker) Enable(url string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rec, ok := h.records[url]
+	if !ok {
+		return false
+	}
+	rec.Disabled = false
+	rec.DisabledAt = time.Time{}
+	rec.ConsecutiveFailures = 0
+	rec.NextAttempt = time.Time{}
+
+	h.save()
+	return true
+}
+
+// Snapshot returns a copy of all records sorted by URL
+func (h *HealthTracker) Snapshot() []FeedHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := make([]FeedHealth, 0, len(h.records))
+	for _, rec := range h.records {
+		records = append(records, *rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].URL < records[j].URL
+	})
+	return records
+}
+
+// record returns the record of a feed, creating it if needed; the caller must hold the lock
+func (h *HealthTracker) record(url string) *FeedHealth {
+	rec, ok := h.records[url]
+	if !ok {
+		rec = &FeedHealth{URL: url}
+		h.records[url] = rec
+	}
+	return rec
+}
+
+// observe counts a fetch and folds its latency into the running average
+func (rec *FeedHealth) observe(latency time.Duration) {
+	rec.TotalFetches++
+	ms := float64(latency) / float64(time.Millisecond)
+	rec.AvgLatencyMs += (ms - rec.AvgLatencyMs) / float64(rec.TotalFetches)
+}
+
+func (h *HealthTracker) load() error {
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var records []*FeedHealth
+	if err := json.Unmarshal(data, &records); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		h.records[rec.URL] = rec
+	}
+	return nil
+}
+
+// save writes the records to disk; the caller must hold the lock
+func (h *HealthTracker) save() {
+	if h.path == "" {
+		return
+	}
+
+	records := make([]*FeedHealth, 0, len(h.records))
+	for _, rec := range h.records {
+		records = append(records, rec)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(h.path), 0755); err == nil {
+			err = utils.WriteFileAtomic(h.path, data, 0644)
+		}
+	}
+	if err != nil {
+		logger.Get().Error().
+			Err(err).
+			Str("path", h.path).
+			Msg("Failed to save feed health records")
+	}
+}
@@ -0,0 +1,84 @@
+package feed
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealthTrackerBackoff(t *testing.T) {
+	h := NewHealthTracker("", time.Minute, 10*time.Minute, 3)
+	url := "https://example.com/feed"
+
+	if ok, _ := h.Allow(url); !ok {
+		t.Fatal("Expected unknown feed to be allowed")
+	}
+
+	h.RecordFailure(url, 100*time.Millisecond, errors.New("timeout"))
+	rec := h.Snapshot()[0]
+	if got := rec.NextAttempt.Sub(rec.LastFailure); got != time.Minute {
+		t.Errorf("Expected first backoff of 1m, got %v", got)
+	}
+	if ok, reason := h.Allow(url); ok || reason == "" {
+		t.Error("Expected feed to back off after a failure")
+	}
+
+	h.RecordFailure(url, 300*time.Millisecond, errors.New("timeout"))
+	rec = h.Snapshot()[0]
+	if got := rec.NextAttempt.Sub(rec.LastFailure); got != 2*time.Minute {
+		t.Errorf("Expected second backoff of 2m, got %v", got)
+	}
+	if rec.AvgLatencyMs != 200 {
+		t.Errorf("Expected average latency of 200ms, got %v", rec.AvgLatencyMs)
+	}
+
+	h.RecordFailure(url, 0, errors.New("status 500"))
+	rec = h.Snapshot()[0]
+	if !rec.Disabled || rec.LastError != "status 500" {
+		t.Errorf("Expected feed to be disabled with last error, got %+v", rec)
+	}
+
+	if !h.Enable(url) {
+		t.Fatal("Expected Enable to find the feed")
+	}
+	if ok, _ := h.Allow(url); !ok {
+		t.Error("Expected re-enabled feed to be allowed")
+	}
+
+	h.RecordSuccess(url, 0, 10)
+	h.RecordSuccess(url, 0, 20)
+	rec = h.Snapshot()[0]
+	if rec.AvgItems != 15 || rec.ConsecutiveFailures != 0 || rec.LastSuccess.IsZero() {
+		t.Errorf("Unexpected record after successes: %+v", rec)
+	}
+}
+
+func TestHealthTrackerBackoffCap(t *testing.T) {
+	h := NewHealthTracker("", time.Minute, 5*time.Minute, 0)
+	url := "https://example.com/feed"
+
+	for i := 0; i < 70; i++ {
+		h.RecordFailure(url, 0, errors.New("down"))
+	}
+	rec := h.Snapshot()[0]
+	if got := rec.NextAttempt.Sub(rec.LastFailure); got != 5*time.Minute {
+		t.Errorf("Expected backoff capped at 5m, got %v", got)
+	}
+	if rec.Disabled {
+		t.Error("Expected feed never to be disabled with a zero threshold")
+	}
+}
+
+func TestHealthTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.json")
+	url := "https://example.com/feed"
+
+	h := NewHealthTracker(path, time.Minute, time.Hour, 1)
+	h.RecordFailure(url, 0, errors.New("down"))
+
+	reloaded := NewHealthTracker(path, time.Minute, time.Hour, 1)
+	if ok, _ := reloaded.Allow(url); ok {
+		t.Error("Expected disabled state to survive a reload")
+	}
+}
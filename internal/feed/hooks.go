@@ -0,0 +1,253 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/go-resty/resty/v2"
+)
+
+// PreFetchHook prepares the request for a feed before it is sent, e.g. by
+// waking the service up or adding credentials. Hooks send their own requests
+// with the fetcher's client, so they wait for their host's slot like fetches.
+type PreFetchHook interface {
+	Before(ctx context.Context, req *resty.Request, feedURL string) error
+}
+
+// newHook builds the hook described by cfg
+func newHook(f *Fetcher, cfg config.HookConfig) (PreFetchHook, error) {
+	var hook PreFetchHook
+	switch cfg.Type {
+	case config.HookWarmUp:
+		hook = &warmUpHook{
+			fetcher:      f,
+			pingURL:      cfg.URL,
+			pollInterval: durationOr(cfg.PollInterval, 2*time.Second),
+			timeout:      durationOr(cfg.Timeout, 60*time.Second),
+			warmFor:      durationOr(cfg.TTL, 5*time.Minute),
+			warm:         make(map[string]time.Time),
+		}
+	case config.HookAuthHeader:
+		value := cfg.Value
+		if cfg.ValueEnv != "" {
+			value = os.Getenv(cfg.ValueEnv)
+		}
+		hook = &authHeaderHook{header: cfg.Header, value: value, valueEnv: cfg.ValueEnv}
+	case config.HookCookie:
+		hook = &cookieHook{
+			fetcher:      f,
+			bootstrapURL: cfg.URL,
+			ttl:          durationOr(cfg.TTL, 30*time.Minute),
+		}
+	default:
+		return nil, fmt.Errorf("unknown hook type %q", cfg.Type)
+	}
+
+	if len(cfg.Hosts) > 0 {
+		hook = &hostFilterHook{hosts: cfg.Hosts, hook: hook}
+	}
+	return hook, nil
+}
+
+// runHooks runs the hooks of a feed in order. Failures are logged and do not
+// stop the fetch, since the feed may still be reachable.
+func (f *Fetcher) runHooks(ctx context.Context, req *resty.Request, feedURL string) {
+	for _, hook := range f.hooksFor(feedURL) {
+		if err := hook.Before(ctx, req, feedURL); err != nil {
+			logger.Get().Warn().
+				Err(err).
+				Str("url", feedURL).
+				Msg("Pre-fetch hook failed, continuing with fetch")
+		}
+	}
+}
+
+// hooksFor returns the hooks of the feed's source, building them on first use
+func (f *Fetcher) hooksFor(feedURL string) []PreFetchHook {
+	src := f.sources.Get(feedURL)
+	key := src.URL
+	if !f.sources.Has(feedURL) {
+		// Unconfigured feeds share the default hooks and their state
+		key = ""
+	}
+
+	f.hooksMu.Lock()
+	defer f.hooksMu.Unlock()
+
+	if hooks, ok := f.hooks[key]; ok {
+		return hooks
+	}
+
+	hooks := make([]PreFetchHook, 0, len(src.Hooks))
+	for _, cfg := range src.Hooks {
+		hook, err := newHook(f, cfg)
+		if err != nil {
+			logger.Get().Error().
+				Err(err).
+				Str("url", feedURL).
+				Msg("Invalid pre-fetch hook")
+			continue
+		}
+		hooks = append(hooks, hook)
+	}
+	f.hooks[key] = hooks
+	return hooks
+}
+
+// hostFilterHook runs its hook only for matching feed hosts
+type hostFilterHook struct {
+	hosts []string
+	hook  PreFetchHook
+}
+
+func (h *hostFilterHook) Before(ctx context.Context, req *resty.Request, feedURL string) error {
+	host := hostOf(feedURL)
+	if i := strings.LastIndexByte(host, ':'); i > 0 {
+		host = host[:i]
+	}
+	for _, domain := range h.hosts {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return h.hook.Before(ctx, req, feedURL)
+		}
+	}
+	return nil
+}
+
+// warmUpHook pings a sleeping service and polls until it is ready, instead
+// of waiting a fixed time. Hosts that answered recently are not pinged again.
+type warmUpHook struct {
+	fetcher      *Fetcher
+	pingURL      string
+	pollInterval time.Duration
+	timeout      time.Duration
+	warmFor      time.Duration
+
+	mu   sync.Mutex
+	warm map[string]time.Time
+}
+
+func (h *warmUpHook) Before(ctx context.Context, req *resty.Request, feedURL string) error {
+	pingURL := h.pingURL
+	if pingURL == "" {
+		parsedURL, err := url.Parse(feedURL)
+		if err != nil {
+			return fmt.Errorf("failed to parse URL: %w", err)
+		}
+		pingURL = fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+	}
+
+	h.mu.Lock()
+	warmUntil := h.warm[pingURL]
+	h.mu.Unlock()
+	if time.Now().Before(warmUntil) {
+		return nil
+	}
+
+	log := logger.Get()
+	log.Info().
+		Str("ping_url", pingURL).
+		Msg("Warming up feed service")
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := h.fetcher.client.R().
+			SetContext(ctx).
+			SetDoNotParseResponse(true).
+			Head(pingURL)
+		if resp != nil && resp.RawBody() != nil {
+			resp.RawBody().Close()
+		}
+
+		if err == nil && resp.StatusCode() >= 200 && resp.StatusCode() < 400 {
+			h.mu.Lock()
+			h.warm[pingURL] = time.Now().Add(h.warmFor)
+			h.mu.Unlock()
+
+			log.Info().
+				Str("ping_url", pingURL).
+				Int("attempts", attempt).
+				Dur("duration", time.Since(start)).
+				Msg("Feed service is ready")
+			return nil
+		}
+
+		timer := time.NewTimer(h.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if err != nil {
+				return fmt.Errorf("service %s not ready after %d attempts: %w", pingURL, attempt, err)
+			}
+			return fmt.Errorf("service %s not ready after %d attempts, last status %d", pingURL, attempt, resp.StatusCode())
+		case <-timer.C:
+		}
+	}
+}
+
+// authHeaderHook injects a static credential header
+type authHeaderHook struct {
+	header   string
+	value    string
+	valueEnv string
+}
+
+func (h *authHeaderHook) Before(ctx context.Context, req *resty.Request, feedURL string) error {
+	if h.value == "" {
+		return fmt.Errorf("auth header %s has no value, is %s set?", h.header, h.valueEnv)
+	}
+	req.SetHeader(h.header, h.value)
+	return nil
+}
+
+// cookieHook visits a bootstrap page and sends the cookies it sets with the
+// feed request, refreshing them once they expire
+type cookieHook struct {
+	fetcher      *Fetcher
+	bootstrapURL string
+	ttl          time.Duration
+
+	mu      sync.Mutex
+	cookies []*http.Cookie
+	expires time.Time
+}
+
+func (h *cookieHook) Before(ctx context.Context, req *resty.Request, feedURL string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cookies == nil || time.Now().After(h.expires) {
+		resp, err := h.fetcher.client.R().
+			SetContext(ctx).
+			Get(h.bootstrapURL)
+		if err != nil {
+			return fmt.Errorf("failed to bootstrap cookies from %s: %w", h.bootstrapURL, err)
+		}
+		if resp.StatusCode() >= 400 {
+			return fmt.Errorf("cookie bootstrap %s returned status code %d", h.bootstrapURL, resp.StatusCode())
+		}
+		h.cookies = resp.Cookies()
+		h.expires = time.Now().Add(h.ttl)
+	}
+
+	req.SetCookies(h.cookies)
+	return nil
+}
+
+func durationOr(d config.Duration, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,165 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+)
+
+const hookTestFeed = `[{"guid":"1","title":"Başlık","content":"İçerik","url":"https://example.com/1"}]`
+
+// newHookFetcher returns a fetcher whose feeds all run hooks
+func newHookFetcher(t *testing.T, interval time.Duration, hooks ...config.HookConfig) *Fetcher {
+	t.Helper()
+	sources, err := config.NewSources(config.Source{Hooks: hooks})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	return NewFetcher(&config.Config{Sources: sources, FeedHostInterval: interval})
+}
+
+// serveFeed answers /feed with a feed of one item
+func serveFeed(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(hookTestFeed))
+}
+
+func TestAuthHeaderAndCookieHooks(t *testing.T) {
+	var mu sync.Mutex
+	logins := 0
+	var gotKey, gotSession string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/login":
+			logins++
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		case "/feed":
+			gotKey = r.Header.Get("X-Api-Key")
+			if c, err := r.Cookie("session"); err == nil {
+				gotSession = c.Value
+			}
+			serveFeed(w)
+		}
+	}))
+	defer server.Close()
+
+	f := newHookFetcher(t, 0,
+		config.HookConfig{Type: config.HookAuthHeader, Header: "X-Api-Key", Value: "secret"},
+		config.HookConfig{Type: config.HookCookie, URL: server.URL + "/login"},
+	)
+	for i := 0; i < 2; i++ {
+		if _, err := f.FetchFeed(context.Background(), server.URL+"/feed"); err != nil {
+			t.Fatalf("FetchFeed failed: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotKey != "secret" {
+		t.Errorf("Expected the auth header to be injected, got %q", gotKey)
+	}
+	if gotSession != "abc" {
+		t.Errorf("Expected the bootstrapped cookie to be sent, got %q", gotSession)
+	}
+	if logins != 1 {
+		t.Errorf("Expected cookies to be bootstrapped once and reused, got %d logins", logins)
+	}
+}
+
+func TestFailingHooksDoNotBlockFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.Error(w, "down", http.StatusInternalServerError)
+			return
+		}
+		serveFeed(w)
+	}))
+	defer server.Close()
+
+	f := newHookFetcher(t, 0,
+		config.HookConfig{Type: config.HookAuthHeader, Header: "X-Api-Key", ValueEnv: "GOEN_TEST_UNSET_KEY"},
+		config.HookConfig{Type: config.HookCookie, URL: server.URL + "/login"},
+	)
+	items, err := f.FetchFeed(context.Background(), server.URL+"/feed")
+	if err != nil || len(items) != 1 {
+		t.Errorf("Expected the feed to be fetched despite failing hooks, got %d items, err %v", len(items), err)
+	}
+}
+
+func TestWarmUpHookPollsThroughLimiter(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		times = append(times, time.Now())
+		if r.Method == http.MethodHead {
+			pings++
+			if pings == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return
+		}
+		serveFeed(w)
+	}))
+	defer server.Close()
+
+	interval := 100 * time.Millisecond
+	f := newHookFetcher(t, interval, config.HookConfig{
+		Type:         config.HookWarmUp,
+		PollInterval: config.Duration(time.Millisecond),
+		Timeout:      config.Duration(5 * time.Second),
+	})
+	if _, err := f.FetchFeed(context.Background(), server.URL+"/feed"); err != nil {
+		t.Fatalf("FetchFeed failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if pings != 2 || len(times) != 3 {
+		t.Fatalf("Expected 2 pings and the fetch, got %d pings and %d requests", pings, len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < interval-10*time.Millisecond {
+			t.Errorf("Expected request %d to wait for the host interval of %v, got %v", i+1, interval, gap)
+		}
+	}
+}
+
+func TestHostFilterHook(t *testing.T) {
+	var mu sync.Mutex
+	var gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		gotKey = r.Header.Get("X-Api-Key")
+		mu.Unlock()
+		serveFeed(w)
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		hosts []string
+		want  string
+	}{
+		{[]string{"example.org"}, ""},
+		{[]string{"127.0.0.1"}, "secret"},
+	} {
+		f := newHookFetcher(t, 0, config.HookConfig{Type: config.HookAuthHeader, Hosts: tc.hosts, Header: "X-Api-Key", Value: "secret"})
+		if _, err := f.FetchFeed(context.Background(), server.URL+"/feed"); err != nil {
+			t.Fatalf("FetchFeed failed: %v", err)
+		}
+		mu.Lock()
+		if gotKey != tc.want {
+			t.Errorf("Expected header %q for hosts %v, got %q", tc.want, tc.hosts, gotKey)
+		}
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,309 @@
+package feed
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// paragraphBreak separates paragraphs in plain text
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+
+// droppedTags are removed together with everything inside them
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Nav: true,
+	atom.Iframe: true, atom.Svg: true, atom.Template: true, atom.Head: true,
+	atom.Form: true, atom.Button: true, atom.Select: true, atom.Object: true,
+}
+
+// HTMLToText converts an HTML fragment to plain text, keeping paragraph
+// breaks, list items, blockquotes, headings and link text. With markdown set
+// the structure is expressed as Markdown (headings, lists, quotes, links and
+// emphasis). Malformed markup is handled by the HTML tokenizer's error recovery.
+func HTMLToText(input string, markdown bool) string {
+	if !strings.Contains(input, "<") {
+		return plainText(input)
+	}
+
+	c := &textConverter{markdown: markdown}
+	z := html.NewTokenizer(strings.NewReader(input))
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := z.Token()
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[token.DataAtom] {
+				if tt == html.StartTagToken {
+					c.dropped = append(c.dropped, token.DataAtom)
+				}
+				continue
+			}
+			if len(c.dropped) > 0 {
+				continue
+			}
+			c.start(token, tt == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			if n := len(c.dropped); n > 0 {
+				if c.dropped[n-1] == token.DataAtom {
+					c.dropped = c.dropped[:n-1]
+				}
+				continue
+			}
+			c.end(token)
+		case html.TextToken:
+			if len(c.dropped) == 0 {
+				c.text(token.Data)
+			}
+		}
+	}
+
+	c.closeLink()
+	return c.result()
+}
+
+type listState struct {
+	ordered bool
+	index   int
+}
+
+// textConverter accumulates text while tracking block structure
+type textConverter struct {
+	markdown bool
+	b        strings.Builder
+
+	dropped []atom.Atom
+	lists   []listState
+	quotes  int
+	pre     int
+
+	// breaks is the number of newlines owed before the next text
+	breaks int
+	// prefix is written at the start of the next line, e.g. a list marker
+	prefix string
+	// space records that whitespace was seen since the last written text
+	space bool
+
+	linkHref  string
+	linkStart int
+	inLink    bool
+}
+
+func (c *textConverter) start(t html.Token, selfClosing bool) {
+	switch t.DataAtom {
+	case atom.P, atom.Table, atom.Pre, atom.Figure, atom.Dl:
+		c.block(2)
+		if t.DataAtom == atom.Pre {
+			c.pre++
+		}
+	case atom.Div, atom.Section, atom.Article, atom.Main, atom.Tr, atom.Dt, atom.Dd, atom.Figcaption, atom.Header, atom.Footer, atom.Aside:
+		c.block(1)
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		c.block(2)
+		if c.markdown {
+			level, _ := strconv.Atoi(t.Data[1:])
+			c.prefix = strings.Repeat("#", level) + " "
+		}
+	case atom.Blockquote:
+		c.block(2)
+		c.quotes++
+	case atom.Ul, atom.Ol:
+		c.block(2)
+		c.lists = append(c.lists, listState{ordered: t.DataAtom == atom.Ol})
+	case atom.Li:
+		c.block(1)
+		marker := "- "
+		if n := len(c.lists); n > 0 {
+			list := &c.lists[n-1]
+			list.index++
+			if list.ordered {
+				marker = strconv.Itoa(list.index) + ". "
+			}
+			marker = strings.Repeat("  ", n-1) + marker
+		}
+		c.prefix = marker
+	case atom.Br:
+		c.block(1)
+	case atom.Hr:
+		c.block(2)
+		if c.markdown {
+			c.write("---")
+			c.block(2)
+		}
+	case atom.Td, atom.Th:
+		c.space = true
+	case atom.A:
+		if c.markdown && !selfClosing {
+			c.closeLink()
+			for _, a := range t.Attr {
+				if a.Key == "href" {
+					c.linkHref = strings.TrimSpace(a.Val)
+				}
+			}
+			if c.linkHref != "" && !strings.HasPrefix(c.linkHref, "#") && !strings.HasPrefix(strings.ToLower(c.linkHref), "javascript:") {
+				c.write("[")
+				c.linkStart = c.b.Len()
+				c.inLink = true
+			}
+		}
+	case atom.Strong, atom.B:
+		if c.markdown {
+			c.write("**")
+		}
+	case atom.Em, atom.I:
+		if c.markdown {
+			c.write("_")
+		}
+	}
+}
+
+func (c *textConverter) end(t html.Token) {
+	switch t.DataAtom {
+	case atom.P, atom.Table, atom.Figure, atom.Dl, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		c.block(2)
+	case atom.Pre:
+		if c.pre > 0 {
+			c.pre--
+		}
+		c.block(2)
+	case atom.Div, atom.Section, atom.Article, atom.Main, atom.Tr, atom.Li, atom.Dt, atom.Dd, atom.Figcaption, atom.Header, atom.Footer, atom.Aside:
+		c.block(1)
+	case atom.Blockquote:
+		if c.quotes > 0 {
+			c.quotes--
+		}
+		c.block(2)
+	case atom.Ul, atom.Ol:
+		if n := len(c.lists); n > 0 {
+			c.lists = c.lists[:n-1]
+		}
+		c.block(2)
+	case atom.A:
+		c.closeLink()
+	case atom.Strong, atom.B:
+		if c.markdown {
+			// Closing markers attach to the preceding word
+			c.b.WriteString("**")
+		}
+	case atom.Em, atom.I:
+		if c.markdown {
+			c.b.WriteString("_")
+		}
+	}
+}
+
+// text writes a text node, collapsing whitespace outside <pre>
+func (c *textConverter) text(data string) {
+	if c.pre > 0 {
+		lines := strings.Split(data, "\n")
+		for i, line := range lines {
+			if i > 0 {
+				c.block(1)
+			}
+			if line != "" {
+				c.write(line)
+			}
+		}
+		return
+	}
+
+	if data != "" && isSpace(data[0]) {
+		c.space = true
+	}
+	words := strings.Fields(data)
+	for i, w := range words {
+		if i > 0 {
+			c.space = true
+		}
+		c.write(w)
+	}
+	if data != "" && isSpace(data[len(data)-1]) {
+		c.space = true
+	}
+}
+
+// write emits s, first flushing pending line breaks, quote markers and prefixes
+func (c *textConverter) write(s string) {
+	if c.b.Len() > 0 && c.breaks > 0 {
+		c.b.WriteString(strings.Repeat("\n", c.breaks))
+		c.lineStart()
+	} else if c.b.Len() == 0 {
+		c.lineStart()
+	} else if c.space && !c.atLineStart() {
+		c.b.WriteByte(' ')
+	}
+	c.breaks = 0
+	c.space = false
+	c.b.WriteString(s)
+}
+
+// lineStart writes the quote markers and pending prefix of a new line
+func (c *textConverter) lineStart() {
+	if c.markdown && c.quotes > 0 {
+		c.b.WriteString(strings.Repeat("> ", c.quotes))
+	}
+	c.b.WriteString(c.prefix)
+	c.prefix = ""
+}
+
+func (c *textConverter) atLineStart() bool {
+	s := c.b.String()
+	return s == "" || strings.HasSuffix(s, "\n") || strings.HasSuffix(s, "> ") || strings.HasSuffix(s, "- ") || strings.HasSuffix(s, "[")
+}
+
+// block requests at least n line breaks before the next text
+func (c *textConverter) block(n int) {
+	if n > c.breaks {
+		c.breaks = n
+	}
+	c.space = false
+}
+
+func (c *textConverter) closeLink() {
+	if !c.inLink {
+		return
+	}
+	c.inLink = false
+	if c.b.Len() == c.linkStart {
+		// Empty link text, drop the opening bracket
+		s := c.b.String()
+		c.b.Reset()
+		c.b.WriteString(s[:len(s)-1])
+	} else {
+		c.b.WriteString("](" + c.linkHref + ")")
+	}
+	c.linkHref = ""
+}
+
+// result trims trailing spaces from lines and surrounding blank lines
+func (c *textConverter) result() string {
+	lines := strings.Split(c.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// plainText normalizes text without markup, keeping blank-line separated paragraphs
+func plainText(input string) string {
+	input = strings.ReplaceAll(html.UnescapeString(input), "\r\n", "\n")
+
+	var paragraphs []string
+	for _, block := range paragraphBreak.Split(input, -1) {
+		if text := strings.Join(strings.Fields(block), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r' || b == '\f'
+}
@@ -0,0 +1,57 @@
+package feed
+
+import "testing"
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		markdown bool
+		want     string
+	}{
+		{
+			name: "paragraphs",
+			in:   "<p>Birinci   paragraf.</p>\n<p>İkinci<br>satır.</p>",
+			want: "Birinci paragraf.\n\nİkinci\nsatır.",
+		},
+		{
+			name: "lists and headings",
+			in:   "<h2>Öne çıkanlar</h2><ul><li>Bir</li><li>İki</li></ul><ol><li>İlk</li><li>Son</li></ol>",
+			want: "Öne çıkanlar\n\n- Bir\n- İki\n\n1. İlk\n2. Son",
+		},
+		{
+			name: "script, style and nav are dropped",
+			in:   "<nav><a href=\"/\">Anasayfa</a></nav><script>var x = '<p>no</p>';</script><style>p{}</style><p>Metin &amp; haber</p>",
+			want: "Metin & haber",
+		},
+		{
+			name: "link text kept in text mode",
+			in:   "<p>Detaylar <a href=\"https://example.com\">burada</a>.</p>",
+			want: "Detaylar burada.",
+		},
+		{
+			name:     "markdown",
+			in:       "<h3>Başlık</h3><p>Bir <strong>önemli</strong> <a href=\"https://example.com/x\">bağlantı</a>.</p><blockquote><p>Alıntı</p></blockquote><ul><li>Madde</li></ul>",
+			markdown: true,
+			want:     "### Başlık\n\nBir **önemli** [bağlantı](https://example.com/x).\n\n> Alıntı\n\n- Madde",
+		},
+		{
+			name: "malformed markup",
+			in:   "<div><p>Kapanmamış paragraf<p>İkinci <b>kalın</div><li>madde",
+			want: "Kapanmamış paragraf\n\nİkinci kalın\n- madde",
+		},
+		{
+			name: "plain text keeps paragraphs",
+			in:   "Birinci paragraf\ndevam ediyor.\n\nİkinci paragraf &amp; son.",
+			want: "Birinci paragraf devam ediyor.\n\nİkinci paragraf & son.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.in, tt.markdown); got != tt.want {
+				t.Errorf("HTMLToText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
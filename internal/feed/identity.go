@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/utils"
+)
+
+// newsIDSpace is the UUID namespace of news item IDs
+var newsIDSpace = uuid.MustParse("5f0c6a1e-2b7d-4c39-9a51-8d3e6f1b7c20")
+
+// NewsID returns the stable news item ID for an item key, so regenerating
+// a source item revises its article instead of creating another one
+func NewsID(key string) string {
+	return uuid.NewSHA1(newsIDSpace, []byte(key)).String()
+}
+
+// ItemKey returns the dedup key of a feed item for the given identity strategy.
+// Strategies fall back to the next available identity when their field is empty.
+func ItemKey(item models.FeedItem, strategy string) string {
+	switch strategy {
+	case config.IdentityURL:
+		if u := itemURL(item); u != "" {
+			return config.IdentityURL + ":" + utils.Hash(u)
+		}
+	case config.IdentityContent:
+		return config.IdentityContent + ":" + ContentHash(item)
+	}
+
+	if item.Guid != "" {
+		return config.IdentityGUID + ":" + utils.Hash(item.Guid)
+	}
+	if u := itemURL(item); u != "" {
+		return config.IdentityURL + ":" + utils.Hash(u)
+	}
+	return config.IdentityContent + ":" + ContentHash(item)
+}
+
+// itemURL prefers the canonical URL so that URL variants share one identity
+func itemURL(item models.FeedItem) string {
+	if item.CanonicalUrl != "" {
+		return item.CanonicalUrl
+	}
+	return item.Url
+}
+
+// ContentHash hashes the normalized title and content of a feed item
+func ContentHash(item models.FeedItem) string {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	return utils.Hash(normalize(item.TitleTR) + "\n" + normalize(item.ContentTR))
+}
+
+// legacyKey returns the key used before identity strategies were introduced,
+// when processed items were marked by the hash of their GUID
+func legacyKey(item models.FeedItem) string {
+	if item.Guid == "" {
+		return ""
+	}
+	return utils.Hash(item.Guid)
+}
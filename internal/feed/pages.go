@@ -0,0 +1,158 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// articlePage is a fetched article page
+type articlePage struct {
+	body     []byte
+	finalURL string
+}
+
+// articlePages caches the article pages fetched during one processing run, keyed by item URL
+type articlePages struct {
+	mu    sync.Mutex
+	pages map[string]*articlePage
+}
+
+func newArticlePages() *articlePages {
+	return &articlePages{pages: make(map[string]*articlePage)}
+}
+
+func (a *articlePages) get(url string) (*articlePage, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	page, ok := a.pages[url]
+	return page, ok
+}
+
+func (a *articlePages) set(url string, page *articlePage) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pages[url] = page
+}
+
+// needsCanonical reports whether the item's source resolves canonical links
+func (p *Processor) needsCanonical(item models.FeedItem) bool {
+	return p.sources.Get(item.Source).ResolveCanonical
+}
+
+// needsExtraction reports whether the item's source extracts full articles
+// and the feed content is short enough to count as a teaser
+func (p *Processor) needsExtraction(item models.FeedItem) bool {
+	src := p.sources.Get(item.Source)
+	if !src.ExtractArticle {
+		return false
+	}
+	return src.ExtractMinLength <= 0 || utf8.RuneCountInString(item.ContentTR) < src.ExtractMinLength
+}
+
+// fetchPages concurrently fetches the article pages of the items selected by
+// want that are not cached yet. Failed fetches are logged and skipped.
+func (p *Processor) fetchPages(ctx context.Context, items []models.FeedItem, want func(models.FeedItem) bool, pages *articlePages) {
+	log := logger.Get()
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, p.fetcher.maxConcurrent)
+
+	for _, item := range items {
+		if item.Url == "" || !want(item) {
+			continue
+		}
+		if _, ok := pages.get(item.Url); ok {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(pageURL string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			body, finalURL, err := p.fetcher.FetchPage(ctx, pageURL)
+			if err != nil {
+				log.Debug().
+					Err(err).
+					Str("url", pageURL).
+					Msg("Failed to fetch article page")
+				return
+			}
+			pages.set(pageURL, &articlePage{body: body, finalURL: finalURL})
+		}(item.Url)
+	}
+
+	wg.Wait()
+}
+
+// resolveCanonicalURLs replaces the canonical URL of items whose page was
+// fetched with the one declared by the page. Items without a page keep their
+// normalized URL.
+func (p *Processor) resolveCanonicalURLs(items []models.FeedItem, pages *articlePages) {
+	for i := range items {
+		if !p.needsCanonical(items[i]) {
+			continue
+		}
+		page, ok := pages.get(items[i].Url)
+		if !ok {
+			continue
+		}
+		canonical, err := p.parser.canonicalizer.FromHTML(page.finalURL, bytes.NewReader(page.body))
+		if err != nil {
+			continue
+		}
+		items[i].CanonicalUrl = canonical
+	}
+}
+
+// extractArticles replaces teaser content with the main text of the article
+// page and fills in the lead image and page metadata
+func (p *Processor) extractArticles(items []models.FeedItem, pages *articlePages) {
+	log := logger.Get()
+
+	for i := range items {
+		item := &items[i]
+		if !p.needsExtraction(*item) {
+			continue
+		}
+		page, ok := pages.get(item.Url)
+		if !ok {
+			continue
+		}
+
+		article, err := p.extractor.Extract(page.finalURL, bytes.NewReader(page.body))
+		if err != nil {
+			log.Debug().
+				Err(err).
+				Str("url", item.Url).
+				Msg("Failed to extract article content")
+			continue
+		}
+
+		if utf8.RuneCountInString(article.Content) > utf8.RuneCountInString(item.ContentTR) {
+			item.ContentTR = article.Content
+		}
+		if item.Image == "" {
+			item.Image = article.Image
+		}
+		if len(article.Metadata) > 0 {
+			item.Metadata = article.Metadata
+		}
+
+		log.Debug().
+			Str("url", item.Url).
+			Int("content_length", utf8.RuneCountInString(item.ContentTR)).
+			Msg("Extracted full article content")
+	}
+}
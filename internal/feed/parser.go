@@ -3,47 +3,84 @@ package feed
 import (
 	"context"
 	"fmt"
-	"html"
-	"regexp"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/bilgisen/goen/internal/config"
 	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
 )
 
 // Parser handles cleaning and normalizing feed items
 type Parser struct {
-	htmlTagRegex *regexp.Regexp
+	canonicalizer *Canonicalizer
+	sources       *config.Sources
+	categories    *config.Categories
+	rejections    *rejectionCounter
+
+	filtersMu sync.Mutex
+	filters   map[string]*itemFilter
 }
 
-func NewParser() *Parser {
+func NewParser(cfg *config.Config) *Parser {
 	return &Parser{
-		htmlTagRegex: regexp.MustCompile(`<[^>]*>`),
+		canonicalizer: NewCanonicalizer(cfg.URLStripParams),
+		sources:       cfg.Sources,
+		categories:    cfg.Categories,
+		rejections:    newRejectionCounter(),
+		filters:       make(map[string]*itemFilter),
+	}
+}
+
+// Rejections returns how many items the source filters rejected, by source and reason
+func (p *Parser) Rejections() map[string]map[string]int {
+	return p.rejections.Snapshot()
+}
+
+// CanonicalURL returns the canonical form of an article URL, or the trimmed
+// input if it cannot be canonicalized
+func (p *Parser) CanonicalURL(rawURL string) string {
+	canonical, err := p.canonicalizer.Canonicalize(rawURL)
+	if err != nil {
+		return strings.TrimSpace(rawURL)
 	}
+	return canonical
 }
 
-// CleanHTML removes HTML tags and normalizes whitespace
+// CleanHTML converts HTML to a single line of text, e.g. for titles
 func (p *Parser) CleanHTML(input string) string {
-	// Remove HTML tags
-	cleaned := p.htmlTagRegex.ReplaceAllString(input, " ")
-	// Unescape HTML entities
-	cleaned = html.UnescapeString(cleaned)
-	// Normalize whitespace
-	cleaned = strings.Join(strings.Fields(cleaned), " ")
-	return strings.TrimSpace(cleaned)
+	return strings.Join(strings.Fields(HTMLToText(input, false)), " ")
+}
+
+// CleanContent converts HTML article content to text that keeps paragraphs,
+// lists, quotes and headings, as Markdown if the source asks for it
+func (p *Parser) CleanContent(input, source string) string {
+	return HTMLToText(input, p.sources.Get(source).MarkdownContent)
 }
 
 // NormalizeFeedItem cleans and validates a single feed item
 func (p *Parser) NormalizeFeedItem(item models.FeedItem) models.FeedItem {
-	return models.FeedItem{
-		Guid:      strings.TrimSpace(item.Guid),
-		TitleTR:   p.CleanHTML(item.TitleTR),
-		ContentTR: p.CleanHTML(item.ContentTR),
-		Image:     strings.TrimSpace(item.Image),
-		Url:       strings.TrimSpace(item.Url),
-		Category:  strings.TrimSpace(item.Category),
+	normalized := models.FeedItem{
+		Guid:         strings.TrimSpace(item.Guid),
+		TitleTR:      p.CleanHTML(item.TitleTR),
+		ContentTR:    p.CleanContent(item.ContentTR, item.Source),
+		Image:        strings.TrimSpace(item.Image),
+		Url:          strings.TrimSpace(item.Url),
+		CanonicalUrl: strings.TrimSpace(item.CanonicalUrl),
+		Category:     strings.TrimSpace(item.Category),
+		Source:       item.Source,
+		PublishedAt:  item.PublishedAt,
+		Metadata:     item.Metadata,
+		Flags:        append([]string(nil), item.Flags...),
 	}
+	if normalized.CanonicalUrl == "" && normalized.Url != "" {
+		normalized.CanonicalUrl = p.CanonicalURL(normalized.Url)
+	}
+	if slug, ok := p.categories.Map(p.sources.Get(item.Source), normalized.Category); ok {
+		normalized.CategorySlug = slug
+	}
+	return normalized
 }
 
 // ValidateFeedItem checks if the feed item has the required fields
@@ -57,10 +94,14 @@ func (p *Parser) ValidateFeedItem(item models.FeedItem) error {
 	if item.Url == "" {
 		return fmt.Errorf("missing required field: url")
 	}
+	if hasEncodingDamage(item.TitleTR) {
+		return fmt.Errorf("title contains invalid or mis-decoded UTF-8")
+	}
 	return nil
 }
 
-// ProcessFeedItems concurrently processes a slice of feed items
+// ProcessFeedItems concurrently processes a slice of feed items. Items
+// rejected by the filters of their source are dropped and counted.
 func (p *Parser) ProcessFeedItems(ctx context.Context, items []models.FeedItem) ([]models.FeedItem, []error) {
 	log := logger.Get()
 	log.Debug().
@@ -71,6 +112,8 @@ func (p *Parser) ProcessFeedItems(ctx context.Context, items []models.FeedItem)
 	var mu sync.Mutex
 	var validItems []models.FeedItem
 	var errors []error
+	rejected := make(map[string]int)
+	now := time.Now()
 
 	semaphore := make(chan struct{}, 10) // Limit concurrent processing
 
@@ -106,6 +149,29 @@ func (p *Parser) ProcessFeedItems(ctx context.Context, items []models.FeedItem)
 				return
 			}
 
+			if filter := p.filterFor(normalized.Source); filter != nil {
+				if reason := filter.Check(normalized, now); reason != "" {
+					log.Debug().
+						Str("guid", item.Guid).
+						Str("title", normalized.TitleTR).
+						Str("reason", reason).
+						Msg("Feed item rejected by source filters")
+					p.rejections.add(normalized.Source, reason)
+					mu.Lock()
+					rejected[reason]++
+					mu.Unlock()
+					return
+				}
+			}
+
+			if hasEncodingDamage(normalized.ContentTR) {
+				log.Warn().
+					Str("guid", item.Guid).
+					Str("source", item.Source).
+					Msg("Feed item content contains invalid or mis-decoded UTF-8")
+				normalized.Flags = append(normalized.Flags, models.FlagEncoding)
+			}
+
 			mu.Lock()
 			validItems = append(validItems, normalized)
 			mu.Unlock()
@@ -126,6 +192,7 @@ func (p *Parser) ProcessFeedItems(ctx context.Context, items []models.FeedItem)
 	}
 
 	wg.Wait()
+	logRejections(rejected)
 
 	log.Info().
 		Int("total_processed", len(items)).
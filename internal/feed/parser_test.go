@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func TestNormalizeFeedItemCategory(t *testing.T) {
+	categories, err := config.NewCategories(
+		config.Category{Slug: "economy", Name: "Economy", Labels: []string{"Ekonomi"}},
+		config.Category{Slug: "world", Name: "World", Labels: []string{"Dünya"}},
+		config.Category{Slug: "sports", Name: "Sports", Labels: []string{"Spor"}},
+		config.Category{Slug: "football", Name: "Football", Parent: "sports"},
+	)
+	if err != nil {
+		t.Fatalf("Failed to init categories: %v", err)
+	}
+	sources, err := config.NewSources(config.Source{},
+		config.Source{URL: "https://feeds.example.com/spor", CategoryMap: map[string]string{"Süper Lig": "football", "Spor": "football"}},
+	)
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	parser := NewParser(&config.Config{Sources: sources, Categories: categories})
+
+	tests := []struct {
+		category string
+		source   string
+		want     string
+	}{
+		{"Ekonomi", "https://feeds.example.com/genel", "economy"},
+		{"DÜNYA", "https://feeds.example.com/genel", "world"},
+		{"economy", "https://feeds.example.com/genel", "economy"},
+		{"Spor", "https://feeds.example.com/genel", "sports"},
+		// The source mapping wins over the labels of the taxonomy
+		{"Spor", "https://feeds.example.com/spor", "football"},
+		{"süper lig", "https://feeds.example.com/spor", "football"},
+		{"Magazin", "https://feeds.example.com/genel", ""},
+		{"", "https://feeds.example.com/genel", ""},
+	}
+
+	for _, tt := range tests {
+		item := parser.NormalizeFeedItem(models.FeedItem{Category: tt.category, Source: tt.source})
+		if item.CategorySlug != tt.want {
+			t.Errorf("Expected %q from %s to map to %q, got %q", tt.category, tt.source, tt.want, item.CategorySlug)
+		}
+		if item.Category != tt.category {
+			t.Errorf("Expected the source label %q to be kept, got %q", tt.category, item.Category)
+		}
+	}
+}
+
+func TestProcessFeedItemsFlagsEncodingDamage(t *testing.T) {
+	sources, err := config.NewSources(config.Source{})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	categories, err := config.NewCategories()
+	if err != nil {
+		t.Fatalf("Failed to init categories: %v", err)
+	}
+	parser := NewParser(&config.Config{Sources: sources, Categories: categories})
+
+	// Spare capacity lets an unguarded append write into the caller's array
+	flags := make([]string, 1, 2)
+	flags[0] = "manual"
+	item := models.FeedItem{
+		Guid:      "1",
+		TitleTR:   "Başlık",
+		ContentTR: "Ä°zmir'de bozuk iÃ§erik",
+		Url:       "https://example.com/1",
+		Flags:     flags,
+	}
+
+	valid, errs := parser.ProcessFeedItems(context.Background(), []models.FeedItem{item})
+	if len(errs) != 0 || len(valid) != 1 {
+		t.Fatalf("Expected 1 valid item, got %d items and errors %v", len(valid), errs)
+	}
+	if got := valid[0].Flags; len(got) != 2 || got[1] != models.FlagEncoding {
+		t.Errorf("Expected the item to be flagged %q, got %v", models.FlagEncoding, got)
+	}
+	if extra := flags[:2][1]; extra != "" {
+		t.Errorf("Expected the caller's flags to stay untouched, got %q appended", extra)
+	}
+}
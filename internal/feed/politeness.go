@@ -0,0 +1,85 @@
+package feed
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// hostLimiter spaces out requests to the same host
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+	delays   map[string]time.Duration
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+		delays:   make(map[string]time.Duration),
+	}
+}
+
+// SetDelay raises the spacing for a host, e.g. to honour a robots.txt Crawl-delay
+func (h *hostLimiter) SetDelay(host string, delay time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.delays[strings.ToLower(host)] = delay
+}
+
+// Wait blocks until a request to host is allowed. Each call reserves the next
+// slot, so concurrent callers for the same host are served one interval apart.
+func (h *hostLimiter) Wait(ctx context.Context, host string) error {
+	host = strings.ToLower(host)
+
+	h.mu.Lock()
+	interval := h.interval
+	if delay := h.delays[host]; delay > interval {
+		interval = delay
+	}
+	now := time.Now()
+	at := h.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	h.next[host] = at.Add(interval)
+	h.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// spaceRequests makes every request sent by client wait for its host's
+// slot. Resty runs the middleware before each attempt, so retries and the
+// requests of pre-fetch hooks are spaced out too.
+func (h *hostLimiter) spaceRequests(client *resty.Client) {
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		return h.Wait(req.Context(), hostOf(req.URL))
+	})
+}
+
+// hostOf returns the host of a URL, or the input itself if it cannot be parsed
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Host)
+}
@@ -7,27 +7,56 @@ import (
 	"time"
 
 	"github.com/bilgisen/goen/internal/cache"
+	"github.com/bilgisen/goen/internal/config"
 	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
-	"github.com/bilgisen/goen/internal/utils"
 )
 
 type Processor struct {
-	fetcher *Fetcher
-	parser  *Parser
-	cache   cache.RedisInterface
+	fetcher   *Fetcher
+	parser    *Parser
+	extractor *Extractor
+	cache     cache.RedisInterface
+	sources   *config.Sources
+
+	// migrationTTL is applied to keys migrated from the legacy format
+	migrationTTL time.Duration
+	// claimTTL is the lease held on an item while it is being generated
+	claimTTL time.Duration
 }
 
-func NewProcessor(redisClient cache.RedisInterface) *Processor {
+func NewProcessor(redisClient cache.RedisInterface, cfg *config.Config) *Processor {
 	return &Processor{
-		fetcher: NewFetcher(),
-		parser:  NewParser(),
-		cache:   redisClient,
+		fetcher:      NewFetcher(cfg),
+		parser:       NewParser(cfg),
+		extractor:    NewExtractor(),
+		cache:        redisClient,
+		sources:      cfg.Sources,
+		migrationTTL: cfg.CacheTTL,
+		claimTTL:     cfg.ClaimTTL,
 	}
 }
 
-// ProcessFeeds fetches, parses, and processes feeds from the given URLs
-func (p *Processor) ProcessFeeds(ctx context.Context, feedURLs []string) ([]models.FeedItem, error) {
+// ItemKey returns the dedup key of an item using the identity strategy of its source
+func (p *Processor) ItemKey(item models.FeedItem) string {
+	return ItemKey(item, p.sources.Get(item.Source).Identity)
+}
+
+// Health returns the per-feed health tracker of the fetcher
+func (p *Processor) Health() *HealthTracker {
+	return p.fetcher.Health()
+}
+
+// Rejections returns the filter rejection counts of the parser
+func (p *Processor) Rejections() map[string]map[string]int {
+	return p.parser.Rejections()
+}
+
+// ProcessFeeds fetches, parses, and processes feeds from the given URLs.
+// Items of feeds that were fetched are processed even if other feeds failed;
+// the per-feed results tell which feeds failed and why. An error is returned
+// only when no feed could be fetched at all.
+func (p *Processor) ProcessFeeds(ctx context.Context, feedURLs []string) ([]models.FeedItem, FetchResults, error) {
 	log := logger.Get()
 	start := time.Now()
 	log.Info().
@@ -35,19 +64,40 @@ func (p *Processor) ProcessFeeds(ctx context.Context, feedURLs []string) ([]mode
 		Msg("Starting to process feeds")
 
 	// Fetch all feeds concurrently
-	items, err := p.fetcher.FetchMultipleFeeds(ctx, feedURLs)
-	if err != nil {
+	results := p.fetcher.FetchMultipleFeeds(ctx, feedURLs)
+	failed := results.Failed()
+	for _, res := range failed {
 		log.Error().
-			Err(err).
-			Msg("Error fetching feeds")
-		return nil, fmt.Errorf("error fetching feeds: %w", err)
+			Err(res.Err).
+			Str("url", res.URL).
+			Dur("duration", res.Duration).
+			Msg("Error fetching feed")
+	}
+	if len(failed) > 0 && len(failed) == len(results) {
+		return nil, results, fmt.Errorf("all %d feeds failed, first error: %w", len(failed), failed[0].Err)
 	}
 
+	items := results.Items()
 	log.Info().
 		Int("total_items", len(items)).
+		Int("failed_feeds", len(failed)).
 		Dur("fetch_duration", time.Since(start)).
 		Msg("Fetched feed items")
 
+	uniqueItems, err := p.ProcessItems(ctx, items)
+	if err != nil {
+		return nil, results, err
+	}
+	return uniqueItems, results, nil
+}
+
+// ProcessItems validates, normalizes and deduplicates items that were
+// already read from a feed, whether fetched or dropped in as a file. Every
+// returned item is claimed and must be finished with MarkAsProcessed or Release.
+func (p *Processor) ProcessItems(ctx context.Context, items []models.FeedItem) ([]models.FeedItem, error) {
+	log := logger.Get()
+	start := time.Now()
+
 	// Process and validate feed items
 	validItems, errs := p.parser.ProcessFeedItems(ctx, items)
 	if len(errs) > 0 {
@@ -61,6 +111,12 @@ func (p *Processor) ProcessFeeds(ctx context.Context, feedURLs []string) ([]mode
 		Dur("validation_duration", time.Since(start)).
 		Msg("Validated feed items")
 
+	// Follow canonical links for sources that ask for it. Fetched pages are
+	// kept for article extraction later in the run.
+	pages := newArticlePages()
+	p.fetchPages(ctx, validItems, p.needsCanonical, pages)
+	p.resolveCanonicalURLs(validItems, pages)
+
 	// Filter out duplicates using cache
 	uniqueItems, err := p.filterDuplicates(ctx, validItems)
 	if err != nil {
@@ -70,15 +126,21 @@ func (p *Processor) ProcessFeeds(ctx context.Context, feedURLs []string) ([]mode
 		return nil, fmt.Errorf("error filtering duplicates: %w", err)
 	}
 
+	// Replace teasers with the full article for sources that ask for it
+	p.fetchPages(ctx, uniqueItems, p.needsExtraction, pages)
+	p.extractArticles(uniqueItems, pages)
+
 	log.Info().
 		Int("unique_items", len(uniqueItems)).
 		Dur("total_duration", time.Since(start)).
-		Msg("Finished processing feeds")
+		Msg("Finished processing feed items")
 
 	return uniqueItems, nil
 }
 
-// filterDuplicates removes items that have already been processed
+// filterDuplicates removes items that have already been processed or are
+// being processed elsewhere. Every returned item is claimed by this caller and
+// must be finished with MarkAsProcessed or Release.
 func (p *Processor) filterDuplicates(ctx context.Context, items []models.FeedItem) ([]models.FeedItem, error) {
 	log := logger.Get()
 	start := time.Now()
@@ -127,8 +189,7 @@ func (p *Processor) filterDuplicates(ctx context.Context, items []models.FeedIte
 				return
 			}
 
-			hash := utils.Hash(item.Url)
-			isProcessed, err := p.cache.IsProcessed(ctx, hash)
+			token, claimed, err := p.claim(ctx, item)
 			if err != nil {
 				log.Error().
 					Err(err).
@@ -138,11 +199,11 @@ func (p *Processor) filterDuplicates(ctx context.Context, items []models.FeedIte
 				return
 			}
 
-			if isProcessed {
+			if !claimed {
 				log.Debug().
 					Str("url", item.Url).
 					Str("guid", item.Guid).
-					Msg("Skipping already processed item")
+					Msg("Skipping already processed or claimed item")
 				mu.Lock()
 				duplicateCount++
 				mu.Unlock()
@@ -152,6 +213,7 @@ func (p *Processor) filterDuplicates(ctx context.Context, items []models.FeedIte
 					Str("guid", item.Guid).
 					Str("title", item.TitleTR).
 					Msg("Adding new unique item")
+				item.ClaimToken = token
 				mu.Lock()
 				uniqueItems = append(uniqueItems, item)
 				mu.Unlock()
@@ -192,12 +254,69 @@ func (p *Processor) filterDuplicates(ctx context.Context, items []models.FeedIte
 	}
 }
 
-// MarkAsProcessed marks the given URLs as processed in the cache
-func (p *Processor) MarkAsProcessed(ctx context.Context, urls []string, ttl time.Duration) error {
-	for _, url := range urls {
-		hash := utils.Hash(url)
-		if err := p.cache.MarkProcessed(ctx, hash, ttl); err != nil {
-			return fmt.Errorf("error marking %s as processed: %w", url, err)
+// claim reserves an item for processing and returns the token of the
+// claim. It returns false if the item was already processed or is claimed
+// by another run. Items marked under the legacy GUID hash are migrated to
+// their identity key on first sight.
+func (p *Processor) claim(ctx context.Context, item models.FeedItem) (string, bool, error) {
+	key := p.ItemKey(item)
+	token, claimed, err := p.cache.Claim(ctx, key, p.claimTTL)
+	if err != nil || !claimed {
+		return "", false, err
+	}
+
+	legacy := legacyKey(item)
+	if legacy == "" {
+		return token, true, nil
+	}
+	isProcessed, err := p.cache.IsProcessed(ctx, legacy)
+	if err != nil {
+		if releaseErr := p.cache.Release(ctx, key, token); releaseErr != nil {
+			err = fmt.Errorf("%w (release failed: %v)", err, releaseErr)
+		}
+		return "", false, err
+	}
+	if !isProcessed {
+		return token, true, nil
+	}
+
+	logger.Get().Debug().
+		Str("guid", item.Guid).
+		Str("key", key).
+		Msg("Migrating legacy processed key")
+	if err := p.cache.CompleteClaim(ctx, key, token, p.migrationTTL); err != nil {
+		return "", false, fmt.Errorf("error migrating legacy key for %s: %w", item.Guid, err)
+	}
+	return "", false, nil
+}
+
+// Release gives up the claims on items that were not processed successfully,
+// so a later run can pick them up again. Only the claims named by the
+// items' tokens are dropped; items without a token hold no claim.
+func (p *Processor) Release(ctx context.Context, items []models.FeedItem) error {
+	for _, item := range items {
+		if item.ClaimToken == "" {
+			continue
+		}
+		if err := p.cache.Release(ctx, p.ItemKey(item), item.ClaimToken); err != nil {
+			return fmt.Errorf("error releasing %s: %w", item.Guid, err)
+		}
+	}
+	return nil
+}
+
+// MarkAsProcessed marks the given items as processed in the cache. Claimed
+// items are only marked while their claim is still theirs or has lapsed.
+func (p *Processor) MarkAsProcessed(ctx context.Context, items []models.FeedItem, ttl time.Duration) error {
+	for _, item := range items {
+		var err error
+		if item.ClaimToken != "" {
+			err = p.cache.CompleteClaim(ctx, p.ItemKey(item), item.ClaimToken, ttl)
+		} else {
+			err = p.cache.MarkProcessed(ctx, p.ItemKey(item), ttl)
+		}
+		if err != nil {
+			return fmt.Errorf("error marking %s as processed: %w", item.Guid, err)
 		}
 	}
 	return nil
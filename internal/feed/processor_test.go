@@ -0,0 +1,193 @@
+package feed
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/cache"
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/utils"
+)
+
+func newTestProcessor(t *testing.T, sources *config.Sources) (*Processor, cache.RedisInterface) {
+	t.Helper()
+	cfg := &config.Config{CacheTTL: time.Hour, ClaimTTL: time.Minute, Sources: sources}
+	redisClient, err := cache.NewMockRedisClient(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create mock cache: %v", err)
+	}
+	return NewProcessor(redisClient, cfg), redisClient
+}
+
+func TestDedupRoundTrip(t *testing.T) {
+	sources, err := config.NewSources(config.Source{},
+		config.Source{URL: "https://feeds.example.com/guid", Identity: config.IdentityGUID},
+		config.Source{URL: "https://feeds.example.com/url", Identity: config.IdentityURL},
+		config.Source{URL: "https://feeds.example.com/content", Identity: config.IdentityContent},
+	)
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+
+	for _, src := range sources.List {
+		t.Run(src.Identity, func(t *testing.T) {
+			p, _ := newTestProcessor(t, sources)
+			ctx := context.Background()
+			items := []models.FeedItem{
+				{Guid: "1", TitleTR: "Başlık bir", ContentTR: "İçerik", Url: "https://example.com/1", Source: src.URL},
+				{Guid: "2", TitleTR: "Başlık iki", ContentTR: "İçerik", Url: "https://example.com/2", Source: src.URL},
+			}
+
+			unique, err := p.filterDuplicates(ctx, items)
+			if err != nil {
+				t.Fatalf("filterDuplicates failed: %v", err)
+			}
+			if len(unique) != 2 {
+				t.Fatalf("Expected 2 unique items before marking, got %d", len(unique))
+			}
+
+			sort.Slice(unique, func(i, j int) bool { return unique[i].Guid < unique[j].Guid })
+			if err := p.MarkAsProcessed(ctx, unique[:1], time.Hour); err != nil {
+				t.Fatalf("MarkAsProcessed failed: %v", err)
+			}
+			if err := p.Release(ctx, unique[1:]); err != nil {
+				t.Fatalf("Release failed: %v", err)
+			}
+
+			unique, err = p.filterDuplicates(ctx, items)
+			if err != nil {
+				t.Fatalf("filterDuplicates failed: %v", err)
+			}
+			if len(unique) != 1 || unique[0].Guid != "2" {
+				t.Errorf("Expected only item 2 to remain after marking item 1, got %+v", unique)
+			}
+		})
+	}
+}
+
+func TestDedupMigratesLegacyKeys(t *testing.T) {
+	sources, err := config.NewSources(config.Source{Identity: config.IdentityURL})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	p, redisClient := newTestProcessor(t, sources)
+	ctx := context.Background()
+
+	item := models.FeedItem{Guid: "legacy-guid", TitleTR: "Eski haber", Url: "https://example.com/legacy"}
+	if err := redisClient.MarkProcessed(ctx, utils.Hash(item.Guid), time.Hour); err != nil {
+		t.Fatalf("Failed to seed legacy key: %v", err)
+	}
+
+	unique, err := p.filterDuplicates(ctx, []models.FeedItem{item})
+	if err != nil {
+		t.Fatalf("filterDuplicates failed: %v", err)
+	}
+	if len(unique) != 0 {
+		t.Errorf("Expected legacy-marked item to be treated as duplicate, got %+v", unique)
+	}
+
+	migrated, err := redisClient.IsProcessed(ctx, p.ItemKey(item))
+	if err != nil {
+		t.Fatalf("IsProcessed failed: %v", err)
+	}
+	if !migrated {
+		t.Error("Expected legacy key to be migrated to the identity key")
+	}
+}
+
+func TestDedupClaimsItems(t *testing.T) {
+	sources, err := config.NewSources(config.Source{})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	p, _ := newTestProcessor(t, sources)
+	ctx := context.Background()
+	items := []models.FeedItem{
+		{Guid: "1", TitleTR: "Başlık", Url: "https://example.com/1"},
+	}
+
+	claimed, err := p.filterDuplicates(ctx, items)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("Expected first run to claim the item, got %d items, err %v", len(claimed), err)
+	}
+
+	// An overlapping run must not get the item while it is claimed
+	unique, err := p.filterDuplicates(ctx, items)
+	if err != nil || len(unique) != 0 {
+		t.Fatalf("Expected overlapping run to skip the claimed item, got %d items, err %v", len(unique), err)
+	}
+
+	// Items without the claim's token cannot release it
+	if err := p.Release(ctx, items); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if again, _ := p.filterDuplicates(ctx, items); len(again) != 0 {
+		t.Fatalf("Expected a release without token to keep the claim, got %d items", len(again))
+	}
+
+	// A failed run releases the claim so the item is retried
+	if err := p.Release(ctx, claimed); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	unique, err = p.filterDuplicates(ctx, items)
+	if err != nil || len(unique) != 1 {
+		t.Fatalf("Expected released item to be claimable again, got %d items, err %v", len(unique), err)
+	}
+
+	// Releasing after the item was marked done must not clear the mark
+	if err := p.MarkAsProcessed(ctx, unique, time.Hour); err != nil {
+		t.Fatalf("MarkAsProcessed failed: %v", err)
+	}
+	if err := p.Release(ctx, unique); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	unique, err = p.filterDuplicates(ctx, items)
+	if err != nil || len(unique) != 0 {
+		t.Fatalf("Expected processed item to stay processed, got %d items, err %v", len(unique), err)
+	}
+}
+
+func TestDedupLateReleaseKeepsNewClaim(t *testing.T) {
+	sources, err := config.NewSources(config.Source{})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	p, _ := newTestProcessor(t, sources)
+	p.claimTTL = 10 * time.Millisecond
+	ctx := context.Background()
+	items := []models.FeedItem{{Guid: "1", TitleTR: "Başlık", Url: "https://example.com/1"}}
+
+	stale, err := p.filterDuplicates(ctx, items)
+	if err != nil || len(stale) != 1 {
+		t.Fatalf("Expected the first run to claim the item, got %d items, err %v", len(stale), err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Another run takes the item over after the first lease expired
+	p.claimTTL = time.Minute
+	live, err := p.filterDuplicates(ctx, items)
+	if err != nil || len(live) != 1 {
+		t.Fatalf("Expected the second run to claim the expired item, got %d items, err %v", len(live), err)
+	}
+
+	// The first run finishes late; neither its release nor its mark may
+	// touch the live claim
+	if err := p.Release(ctx, stale); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if again, _ := p.filterDuplicates(ctx, items); len(again) != 0 {
+		t.Fatalf("Expected the live claim to survive a stale release, got %d items", len(again))
+	}
+	if err := p.MarkAsProcessed(ctx, stale, time.Hour); err != nil {
+		t.Fatalf("MarkAsProcessed failed: %v", err)
+	}
+	if err := p.Release(ctx, live); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if again, _ := p.filterDuplicates(ctx, items); len(again) != 1 {
+		t.Fatalf("Expected the item to be claimable after the live run released it, got %d items", len(again))
+	}
+}
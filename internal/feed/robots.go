@@ -0,0 +1,249 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// robotsTTL is how long a fetched robots.txt is trusted
+	robotsTTL = 24 * time.Hour
+	// robotsErrorTTL is how long an unreachable robots.txt blocks a host
+	robotsErrorTTL = time.Hour
+)
+
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+// robotsRules are the rules of one host that apply to our user agent
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	disallowed bool // set when robots.txt could not be fetched
+	expires    time.Time
+}
+
+// robotsCache fetches, parses and caches robots.txt files per host.
+// Concurrent lookups of a host share one fetch.
+type robotsCache struct {
+	mu       sync.Mutex
+	client   *resty.Client
+	limiter  *hostLimiter
+	agent    string
+	hosts    map[string]*robotsRules
+	inflight singleflight.Group
+}
+
+func newRobotsCache(client *resty.Client, limiter *hostLimiter, userAgent string) *robotsCache {
+	// Groups are matched on the product token, e.g. "AI-News-Processor" of "AI-News-Processor/1.0"
+	agent := strings.ToLower(strings.TrimSpace(userAgent))
+	if i := strings.IndexAny(agent, "/ "); i > 0 {
+		agent = agent[:i]
+	}
+	return &robotsCache{
+		client:  client,
+		limiter: limiter,
+		agent:   agent,
+		hosts:   make(map[string]*robotsRules),
+	}
+}
+
+// Allowed reports whether robots.txt of the URL's host permits fetching it
+func (r *robotsCache) Allowed(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	rules := r.rulesFor(ctx, u)
+	if rules.disallowed {
+		return false
+	}
+
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return rules.allows(target)
+}
+
+func (r *robotsCache) rulesFor(ctx context.Context, u *url.URL) *robotsRules {
+	host := strings.ToLower(u.Host)
+
+	r.mu.Lock()
+	rules, ok := r.hosts[host]
+	r.mu.Unlock()
+	if ok && time.Now().Before(rules.expires) {
+		return rules
+	}
+
+	v, _, _ := r.inflight.Do(host, func() (interface{}, error) {
+		rules := r.fetch(ctx, u.Scheme+"://"+u.Host+"/robots.txt")
+		if rules.crawlDelay > 0 {
+			r.limiter.SetDelay(host, rules.crawlDelay)
+		}
+
+		r.mu.Lock()
+		r.hosts[host] = rules
+		r.mu.Unlock()
+		return rules, nil
+	})
+	return v.(*robotsRules)
+}
+
+// fetch downloads and parses a robots.txt. Following RFC 9309, a missing
+// file allows everything and an unreachable one disallows everything.
+func (r *robotsCache) fetch(ctx context.Context, robotsURL string) *robotsRules {
+	unreachable := &robotsRules{disallowed: true, expires: time.Now().Add(robotsErrorTTL)}
+
+	resp, err := r.client.R().
+		SetContext(ctx).
+		Get(robotsURL)
+	if err != nil {
+		logger.Get().Debug().
+			Err(err).
+			Str("url", robotsURL).
+			Msg("Failed to fetch robots.txt")
+		return unreachable
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusOK:
+		rules := parseRobots(resp.Body(), r.agent)
+		rules.expires = time.Now().Add(robotsTTL)
+		return rules
+	case resp.StatusCode() >= 400 && resp.StatusCode() < 500:
+		return &robotsRules{expires: time.Now().Add(robotsTTL)}
+	default:
+		return unreachable
+	}
+}
+
+// parseRobots returns the rules of the group whose user-agent equals agent,
+// ignoring case, falling back to "*"
+func parseRobots(data []byte, agent string) *robotsRules {
+	type group struct {
+		rules      []robotsRule
+		crawlDelay time.Duration
+	}
+	var specific, wildcard *group
+	var current []*group
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				current = nil
+				inRules = false
+			}
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				if wildcard == nil {
+					wildcard = &group{}
+				}
+				current = append(current, wildcard)
+			case name != "" && name == agent:
+				if specific == nil {
+					specific = &group{}
+				}
+				current = append(current, specific)
+			default:
+				current = append(current, &group{})
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			for _, g := range current {
+				g.rules = append(g.rules, robotsRule{allow: key == "allow", path: value})
+			}
+		case "crawl-delay":
+			inRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				for _, g := range current {
+					g.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	chosen := specific
+	if chosen == nil {
+		chosen = wildcard
+	}
+	if chosen == nil {
+		return &robotsRules{}
+	}
+	return &robotsRules{rules: chosen.rules, crawlDelay: chosen.crawlDelay}
+}
+
+// allows applies the longest matching rule; allow wins ties
+func (rules *robotsRules) allows(target string) bool {
+	best, allowed := -1, true
+	for _, rule := range rules.rules {
+		if !robotsMatch(rule.path, target) {
+			continue
+		}
+		if n := len(rule.path); n > best || (n == best && rule.allow) {
+			best, allowed = n, rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsMatch matches a robots.txt path pattern supporting "*" and a trailing "$"
+func robotsMatch(pattern, target string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(target, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for _, part := range parts[1:] {
+		i := strings.Index(target[pos:], part)
+		if i < 0 {
+			return false
+		}
+		pos += i + len(part)
+	}
+	if anchored {
+		// The last part must end the target
+		last := parts[len(parts)-1]
+		return strings.HasSuffix(target, last) && (len(parts) > 1 || pos == len(target))
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestParseRobots(t *testing.T) {
+	data := []byte(`
+User-agent: *
+Disallow: /arama
+Allow: /arama/haber$
+Crawl-delay: 2
+
+User-agent: Googlebot
+Disallow: /
+
+User-agent: AI-News-Processor
+Disallow: /uye/
+Disallow: /*.pdf$
+Allow: /uye/acik
+Crawl-delay: 5
+`)
+
+	rules := parseRobots(data, "ai-news-processor")
+	if rules.crawlDelay.Seconds() != 5 {
+		t.Errorf("Expected crawl delay of the specific group, got %v", rules.crawlDelay)
+	}
+
+	tests := map[string]bool{
+		"/ekonomi/haber-1":  true,
+		"/arama":            true, // only disallowed for the wildcard group
+		"/uye/profil":       false,
+		"/uye/acik/sayfa":   true,
+		"/belge/rapor.pdf":  false,
+		"/belge/rapor.pdfx": true,
+	}
+	for target, want := range tests {
+		if got := rules.allows(target); got != want {
+			t.Errorf("allows(%q) = %v, want %v", target, got, want)
+		}
+	}
+
+	wildcard := parseRobots(data, "other-bot")
+	if wildcard.allows("/arama?q=x") || !wildcard.allows("/arama/haber") {
+		t.Error("Expected wildcard group rules for unknown agents")
+	}
+}
+
+func TestParseRobotsMatchesWholeAgent(t *testing.T) {
+	data := []byte(`
+User-agent: *
+Disallow: /arama
+
+User-agent: News
+Disallow: /
+
+User-agent:
+Disallow: /
+`)
+
+	// Neither a group naming a part of our token nor an empty user-agent applies
+	rules := parseRobots(data, "ai-news-processor")
+	if !rules.allows("/ekonomi") || rules.allows("/arama") {
+		t.Error("Expected the wildcard group for an agent no group names exactly")
+	}
+
+	rules = parseRobots(data, "news")
+	if rules.allows("/ekonomi") {
+		t.Error("Expected the group naming the agent in another case to apply")
+	}
+}
+
+func TestRobotsCacheFetchesOncePerHost(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("User-agent: *\nDisallow: /gizli\n"))
+	}))
+	defer server.Close()
+
+	robots := newRobotsCache(resty.New(), newHostLimiter(0), "AI-News-Processor/1.0")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !robots.Allowed(context.Background(), server.URL+"/haber") {
+				t.Error("Expected /haber to be allowed")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d fetches", n)
+	}
+}
@@ -0,0 +1,180 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+const (
+	// archiveDir receives files that were processed
+	archiveDir = "archive"
+	// failedDir receives files that could not be parsed
+	failedDir = "failed"
+	// settleTime is how long a file must be left unmodified before it is
+	// read, so files still being written are not picked up half-way
+	settleTime = 2 * time.Second
+)
+
+// watchExtensions are the feed file types picked up by the watcher
+var watchExtensions = map[string]bool{
+	".json": true,
+	".xml":  true,
+	".rss":  true,
+	".atom": true,
+}
+
+// FileHandler runs the items of a dropped feed file through the pipeline.
+// The file is archived when it returns nil and retried on the next scan otherwise.
+type FileHandler func(ctx context.Context, path string, items []models.FeedItem) error
+
+// Watcher polls a directory for feed files dropped in by other tools
+type Watcher struct {
+	dir      string
+	interval time.Duration
+	handle   FileHandler
+}
+
+// NewWatcher creates a watcher for dir that scans every interval
+func NewWatcher(dir string, interval time.Duration, handle FileHandler) *Watcher {
+	return &Watcher{dir: dir, interval: interval, handle: handle}
+}
+
+// Run scans the directory until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	log := logger.Get()
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		log.Error().
+			Err(err).
+			Str("dir", w.dir).
+			Msg("Failed to create feed source directory, file watcher disabled")
+		return
+	}
+
+	log.Info().
+		Str("dir", w.dir).
+		Dur("interval", w.interval).
+		Msg("Watching for feed files")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan processes the settled feed files currently in the directory, oldest first
+func (w *Watcher) Scan(ctx context.Context) {
+	log := logger.Get()
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("dir", w.dir).
+			Msg("Failed to read feed source directory")
+		return
+	}
+
+	type pending struct {
+		path    string
+		modTime time.Time
+	}
+	var files []pending
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if !watchExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < settleTime {
+			continue
+		}
+		files = append(files, pending{path: filepath.Join(w.dir, entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		w.processFile(ctx, file.path)
+	}
+}
+
+func (w *Watcher) processFile(ctx context.Context, path string) {
+	log := logger.Get().With().Str("file", path).Logger()
+
+	items, err := w.readFile(path)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Failed to parse feed file, moving it aside")
+		w.move(path, failedDir)
+		return
+	}
+
+	if err := w.handle(ctx, path, items); err != nil {
+		log.Error().
+			Err(err).
+			Msg("Failed to process feed file, will retry")
+		return
+	}
+
+	log.Info().
+		Int("items", len(items)).
+		Msg("Processed feed file")
+	w.move(path, archiveDir)
+}
+
+// readFile reads and parses a feed file, recording it as the items' source
+func (w *Watcher) readFile(path string) ([]models.FeedItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	body, _, err := DecodeBody(data, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode feed file: %w", err)
+	}
+	return ParseFeed(body, "file://"+filepath.Base(path))
+}
+
+// move moves a file into a dated subfolder, e.g. archive/2025-01-02/feed.json
+func (w *Watcher) move(path, folder string) {
+	dir := filepath.Join(w.dir, folder, time.Now().Format("2006-01-02"))
+	target := filepath.Join(dir, filepath.Base(path))
+	if _, err := os.Stat(target); err == nil {
+		// Keep both when a file of the same name was dropped twice a day
+		ext := filepath.Ext(target)
+		target = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(target, ext), time.Now().UnixNano(), ext)
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		err = os.Rename(path, target)
+	}
+	if err != nil {
+		logger.Get().Error().
+			Err(err).
+			Str("file", path).
+			Str("target", target).
+			Msg("Failed to move feed file")
+	}
+}
@@ -0,0 +1,70 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func writeFeedFile(t *testing.T, dir, name, content string, age time.Duration) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write feed file: %v", err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+	return path
+}
+
+func TestWatcherScan(t *testing.T) {
+	dir := t.TempDir()
+	good := writeFeedFile(t, dir, "good.json", `[{"guid":"1","title":"Başlık","content":"İçerik"}]`, time.Minute)
+	bad := writeFeedFile(t, dir, "bad.xml", `<html></html>`, time.Minute)
+	fresh := writeFeedFile(t, dir, "fresh.json", `[]`, 0)
+	retry := writeFeedFile(t, dir, "retry.rss", `<rss><channel><item><title>Başlık</title></item></channel></rss>`, time.Minute)
+	writeFeedFile(t, dir, "notes.txt", "ignored", time.Minute)
+
+	var handled []string
+	w := NewWatcher(dir, time.Second, func(ctx context.Context, path string, items []models.FeedItem) error {
+		handled = append(handled, filepath.Base(path))
+		if filepath.Base(path) == "retry.rss" {
+			return errors.New("cache unavailable")
+		}
+		if len(items) != 1 || items[0].Source != "file://good.json" {
+			t.Errorf("Unexpected items: %+v", items)
+		}
+		return nil
+	})
+	w.Scan(context.Background())
+
+	if len(handled) != 2 {
+		t.Fatalf("Expected the good and retry files to be handled, got %v", handled)
+	}
+
+	day := time.Now().Format("2006-01-02")
+	if _, err := os.Stat(filepath.Join(dir, archiveDir, day, "good.json")); err != nil {
+		t.Errorf("Expected good.json to be archived: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, failedDir, day, "bad.xml")); err != nil {
+		t.Errorf("Expected bad.xml to be moved to failed: %v", err)
+	}
+	for _, path := range []string{fresh, retry} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to stay in place: %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(good); !os.IsNotExist(err) {
+		t.Error("Expected good.json to be gone from the source directory")
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Error("Expected bad.xml to be gone from the source directory")
+	}
+}
@@ -0,0 +1,69 @@
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"guid":"1","title":"Başlık"}`)
+	secret := "s3cret"
+
+	if err := VerifySignature(secret, body, Sign(secret, body)); err != nil {
+		t.Errorf("Expected own signature to verify, got %v", err)
+	}
+
+	tests := map[string]error{
+		"":                            ErrMissingSignature,
+		"sha256":                      ErrInvalidSignature,
+		"md5=abcd":                    ErrInvalidSignature,
+		"sha256=not-hex":              ErrInvalidSignature,
+		Sign("other", body):           ErrInvalidSignature,
+		Sign(secret, []byte("other")): ErrInvalidSignature,
+	}
+	for header, want := range tests {
+		if err := VerifySignature(secret, body, header); !errors.Is(err, want) {
+			t.Errorf("VerifySignature(%q) = %v, want %v", header, err, want)
+		}
+	}
+}
+
+func TestVerifyPush(t *testing.T) {
+	body := []byte(`{"guid":"1","title":"Başlık"}`)
+	secret := "s3cret"
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	if err := VerifyPush(secret, body, SignPush(secret, now, body), ts, now); err != nil {
+		t.Errorf("Expected own signature to verify, got %v", err)
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(pushPayload(ts, body))
+	sha1Sig := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		header    string
+		timestamp string
+		want      error
+	}{
+		{"missing signature", "", ts, ErrMissingSignature},
+		{"sha1", sha1Sig, ts, ErrInvalidSignature},
+		{"body only", Sign(secret, body), ts, ErrInvalidSignature},
+		{"other timestamp", SignPush(secret, now.Add(-time.Minute), body), ts, ErrInvalidSignature},
+		{"missing timestamp", SignPush(secret, now, body), "", ErrStalePush},
+		{"old push", SignPush(secret, now.Add(-time.Hour), body), strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), ErrStalePush},
+		{"future push", SignPush(secret, now.Add(time.Hour), body), strconv.FormatInt(now.Add(time.Hour).Unix(), 10), ErrStalePush},
+	}
+	for _, tc := range tests {
+		if err := VerifyPush(secret, body, tc.header, tc.timestamp, now); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
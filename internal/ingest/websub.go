@@ -0,0 +1,310 @@
+package ingest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// Subscription states
+const (
+	StatePending = "pending" // subscription requested, waiting for the hub to verify it
+	StateActive  = "active"
+	StateDenied  = "denied" // the hub refused the subscription
+	StateFailed  = "failed" // the subscription request failed
+)
+
+const (
+	// defaultLease is requested when a source does not set one
+	defaultLease = 7 * 24 * time.Hour
+	// checkInterval is how often subscriptions are checked for renewal
+	checkInterval = time.Minute
+	// retryAfter is how long pending, failed and denied subscriptions wait
+	// before they are requested again
+	retryAfter = time.Hour
+)
+
+var (
+	// ErrUnknownSubscription is returned for callbacks of subscriptions we do not hold
+	ErrUnknownSubscription = errors.New("unknown subscription")
+	// ErrTopicMismatch is returned when a verification names another topic
+	ErrTopicMismatch = errors.New("topic does not match subscription")
+)
+
+// Subscription is a WebSub subscription to one source
+type Subscription struct {
+	ID          string    `json:"id"`
+	Hub         string    `json:"hub"`
+	Topic       string    `json:"topic"`
+	Source      string    `json:"source"`
+	State       string    `json:"state"`
+	Lease       int       `json:"lease_seconds,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	RequestedAt time.Time `json:"requested_at,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+
+	requestedLease time.Duration
+	secret         string
+	// prevSecret still verifies deliveries signed before a renewal took effect
+	prevSecret string
+}
+
+// Subscriber subscribes to sources at their WebSub hubs, answers hub
+// verification requests and renews leases before they expire
+type Subscriber struct {
+	client      *resty.Client
+	callbackURL string
+
+	mu   sync.Mutex
+	subs map[string]*Subscription
+}
+
+// NewSubscriber creates a subscriber for every source with a WebSub hub.
+// Hubs call back at callbackURL followed by "/" and the subscription ID.
+func NewSubscriber(callbackURL string, sources *config.Sources) *Subscriber {
+	s := &Subscriber{
+		client:      resty.New().SetTimeout(30 * time.Second),
+		callbackURL: strings.TrimRight(callbackURL, "/"),
+		subs:        make(map[string]*Subscription),
+	}
+	if sources == nil {
+		return s
+	}
+
+	for _, src := range sources.List {
+		if src.WebSub == nil {
+			continue
+		}
+		topic := src.WebSub.Topic
+		if topic == "" {
+			topic = src.URL
+		}
+		lease := time.Duration(src.WebSub.Lease)
+		if lease <= 0 {
+			lease = defaultLease
+		}
+		// The ID is derived from the topic so callbacks survive restarts
+		id := utils.Hash(src.WebSub.Hub + " " + topic)[:16]
+		s.subs[id] = &Subscription{
+			ID:             id,
+			Hub:            src.WebSub.Hub,
+			Topic:          topic,
+			Source:         src.URL,
+			State:          StatePending,
+			requestedLease: lease,
+		}
+	}
+	return s
+}
+
+// Run subscribes to every hub and keeps the subscriptions renewed until ctx is cancelled
+func (s *Subscriber) Run(ctx context.Context) {
+	if len(s.subs) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		for _, id := range s.due(time.Now()) {
+			if err := s.subscribe(ctx, id); err != nil {
+				logger.Get().Error().
+					Err(err).
+					Str("subscription", id).
+					Msg("WebSub subscription request failed")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// due returns the subscriptions that need to be requested or renewed
+func (s *Subscriber) due(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for id, sub := range s.subs {
+		switch {
+		case sub.RequestedAt.IsZero():
+			ids = append(ids, id)
+		case sub.State == StateActive:
+			// Renew once 90% of the lease has passed
+			margin := time.Duration(sub.Lease) * time.Second / 10
+			if now.After(sub.ExpiresAt.Add(-margin)) {
+				ids = append(ids, id)
+			}
+		case now.Sub(sub.RequestedAt) > retryAfter:
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// subscribe sends a subscription request for id to its hub with a fresh secret
+func (s *Subscriber) subscribe(ctx context.Context, id string) error {
+	secret, err := newSecret()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	sub, ok := s.subs[id]
+	if !ok {
+		s.mu.Unlock()
+		return ErrUnknownSubscription
+	}
+	hub, topic, lease := sub.Hub, sub.Topic, sub.requestedLease
+	sub.RequestedAt = time.Now()
+	sub.prevSecret, sub.secret = sub.secret, secret
+	if sub.State != StateActive {
+		sub.State = StatePending
+	}
+	s.mu.Unlock()
+
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{
+			"hub.mode":          "subscribe",
+			"hub.topic":         topic,
+			"hub.callback":      s.callbackURL + "/" + id,
+			"hub.lease_seconds": strconv.Itoa(int(lease.Seconds())),
+			"hub.secret":        secret,
+		}).
+		Post(hub)
+	if err == nil && (resp.StatusCode() < 200 || resp.StatusCode() >= 300) {
+		err = fmt.Errorf("hub %s returned status code %d: %s", hub, resp.StatusCode(), strings.TrimSpace(resp.String()))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if sub.State != StateActive {
+			sub.State = StateFailed
+		}
+		sub.LastError = err.Error()
+		return err
+	}
+
+	logger.Get().Info().
+		Str("hub", hub).
+		Str("topic", topic).
+		Msg("Requested WebSub subscription")
+	return nil
+}
+
+// Verify answers a hub's verification request and returns the challenge to
+// echo. Denials are recorded and acknowledged with an empty challenge.
+func (s *Subscriber) Verify(id, mode, topic, challenge, leaseSeconds, reason string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return "", ErrUnknownSubscription
+	}
+	if topic != sub.Topic {
+		return "", ErrTopicMismatch
+	}
+
+	switch mode {
+	case "subscribe":
+		if challenge == "" {
+			return "", fmt.Errorf("missing hub.challenge")
+		}
+		lease, err := strconv.Atoi(leaseSeconds)
+		if err != nil || lease <= 0 {
+			lease = int(sub.requestedLease.Seconds())
+		}
+		sub.State = StateActive
+		sub.Lease = lease
+		sub.ExpiresAt = time.Now().Add(time.Duration(lease) * time.Second)
+		sub.LastError = ""
+
+		logger.Get().Info().
+			Str("topic", sub.Topic).
+			Int("lease_seconds", lease).
+			Msg("WebSub subscription verified")
+		return challenge, nil
+
+	case "denied":
+		sub.State = StateDenied
+		sub.LastError = reason
+		logger.Get().Warn().
+			Str("topic", sub.Topic).
+			Str("reason", reason).
+			Msg("WebSub subscription denied by hub")
+		return "", nil
+	}
+
+	// We never unsubscribe, so any other mode was not requested by us
+	return "", fmt.Errorf("unexpected hub.mode %q", mode)
+}
+
+// Authenticate checks the X-Hub-Signature of a content delivery and returns
+// the source URL the items belong to
+func (s *Subscriber) Authenticate(id string, body []byte, signature string) (string, error) {
+	s.mu.Lock()
+	sub, ok := s.subs[id]
+	var source string
+	var secrets []string
+	if ok {
+		source, secrets = sub.Source, []string{sub.secret, sub.prevSecret}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return "", ErrUnknownSubscription
+	}
+	err := ErrInvalidSignature
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		if err = VerifySignature(secret, body, signature); err == nil {
+			return source, nil
+		}
+	}
+	return "", err
+}
+
+// Subscriptions returns a copy of all subscriptions sorted by topic
+func (s *Subscriber) Subscriptions() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, *sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].Topic < subs[j].Topic
+	})
+	return subs
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate subscription secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
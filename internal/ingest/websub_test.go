@@ -0,0 +1,128 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/config"
+)
+
+func TestSubscriberFlow(t *testing.T) {
+	requests := make(chan url.Values, 2)
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse subscription request: %v", err)
+		}
+		requests <- r.PostForm
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	sources, err := config.NewSources(config.Source{}, config.Source{
+		URL:    "https://www.example.com/rss",
+		WebSub: &config.WebSubConfig{Hub: hub.URL, Lease: config.Duration(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	s := NewSubscriber("https://news.example.com/api/v1/websub/callback/", sources)
+
+	ids := s.due(time.Now())
+	if len(ids) != 1 {
+		t.Fatalf("Expected one subscription to be due, got %v", ids)
+	}
+	id := ids[0]
+	if err := s.subscribe(context.Background(), id); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	form := <-requests
+	if form.Get("hub.mode") != "subscribe" || form.Get("hub.topic") != "https://www.example.com/rss" {
+		t.Errorf("Unexpected subscription request: %v", form)
+	}
+	if form.Get("hub.callback") != "https://news.example.com/api/v1/websub/callback/"+id {
+		t.Errorf("Unexpected callback: %s", form.Get("hub.callback"))
+	}
+	if form.Get("hub.lease_seconds") != "3600" || form.Get("hub.secret") == "" {
+		t.Errorf("Expected lease and secret, got %v", form)
+	}
+	if len(s.due(time.Now())) != 0 {
+		t.Error("Expected a pending subscription not to be requested again right away")
+	}
+
+	// Verification
+	if _, err := s.Verify(id, "subscribe", "https://evil.example.com/", "abc", "600", ""); !errors.Is(err, ErrTopicMismatch) {
+		t.Errorf("Expected topic mismatch, got %v", err)
+	}
+	if _, err := s.Verify("unknown", "subscribe", "https://www.example.com/rss", "abc", "600", ""); !errors.Is(err, ErrUnknownSubscription) {
+		t.Errorf("Expected unknown subscription, got %v", err)
+	}
+	if _, err := s.Verify(id, "unsubscribe", "https://www.example.com/rss", "abc", "", ""); err == nil {
+		t.Error("Expected unsubscribe verification to be refused")
+	}
+	challenge, err := s.Verify(id, "subscribe", "https://www.example.com/rss", "abc", "600", "")
+	if err != nil || challenge != "abc" {
+		t.Fatalf("Expected challenge to be echoed, got %q, %v", challenge, err)
+	}
+	sub := s.Subscriptions()[0]
+	if sub.State != StateActive || sub.Lease != 600 {
+		t.Errorf("Expected active subscription with granted lease, got %+v", sub)
+	}
+
+	// Content delivery
+	body := []byte(`<rss><channel><item><title>Başlık</title></item></channel></rss>`)
+	source, err := s.Authenticate(id, body, Sign(form.Get("hub.secret"), body))
+	if err != nil || source != "https://www.example.com/rss" {
+		t.Errorf("Expected delivery to authenticate, got %q, %v", source, err)
+	}
+	if _, err := s.Authenticate(id, body, Sign("wrong", body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected bad signature to fail, got %v", err)
+	}
+
+	// Renewal is due once 90% of the lease has passed, and the old secret
+	// keeps working until the hub switches over
+	if len(s.due(time.Now().Add(500*time.Second))) != 0 {
+		t.Error("Expected no renewal early in the lease")
+	}
+	if len(s.due(time.Now().Add(550*time.Second))) != 1 {
+		t.Error("Expected renewal near the end of the lease")
+	}
+	if err := s.subscribe(context.Background(), id); err != nil {
+		t.Fatalf("renewal failed: %v", err)
+	}
+	renewal := <-requests
+	if renewal.Get("hub.secret") == form.Get("hub.secret") {
+		t.Error("Expected a fresh secret on renewal")
+	}
+	if _, err := s.Authenticate(id, body, Sign(form.Get("hub.secret"), body)); err != nil {
+		t.Errorf("Expected the previous secret to verify after renewal, got %v", err)
+	}
+	if s.Subscriptions()[0].State != StateActive {
+		t.Error("Expected subscription to stay active while renewing")
+	}
+}
+
+func TestSubscriberDenied(t *testing.T) {
+	sources, err := config.NewSources(config.Source{}, config.Source{
+		URL:    "https://www.example.com/rss",
+		WebSub: &config.WebSubConfig{Hub: "https://hub.example.com/"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sources: %v", err)
+	}
+	s := NewSubscriber("https://news.example.com/callback", sources)
+	id := s.Subscriptions()[0].ID
+
+	if _, err := s.Verify(id, "denied", "https://www.example.com/rss", "", "", "topic not allowed"); err != nil {
+		t.Fatalf("Expected denial to be acknowledged, got %v", err)
+	}
+	sub := s.Subscriptions()[0]
+	if sub.State != StateDenied || sub.LastError != "topic not allowed" {
+		t.Errorf("Expected denied state with reason, got %+v", sub)
+	}
+}
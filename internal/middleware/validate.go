@@ -2,6 +2,9 @@ package middleware
 
 import (
     "net/http"
+    "reflect"
+    "strings"
+    "time"
 
     "github.com/bilgisen/goen/internal/logger"
     "github.com/go-playground/validator/v10"
@@ -17,20 +20,52 @@ type Validator struct {
 func NewValidator() *Validator {
 	v := validator.New()
 	// Add custom validators here if needed
+	v.RegisterValidation("date", validateDate)
+	// Report fields by the names clients send
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"query", "json"} {
+			if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+		return f.Name
+	})
 	return &Validator{validate: v}
 }
 
+// DateLayouts are the accepted formats of "date" fields: RFC 3339 timestamps
+// or plain dates
+var DateLayouts = []string{time.RFC3339, time.DateOnly}
+
+// validateDate accepts strings in one of DateLayouts
+func validateDate(fl validator.FieldLevel) bool {
+	for _, layout := range DateLayouts {
+		if _, err := time.Parse(layout, fl.Field().String()); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// newLike returns a new zero value of the type s points to, so each request
+// is parsed into its own value instead of sharing one
+func newLike(s interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(s).Elem()).Interface()
+}
+
 // Validate validates the request body against the provided struct
 func (v *Validator) Validate(s interface{}) error {
 	return v.validate.Struct(s)
 }
 
-// ValidateRequest is a middleware that validates the request body
+// ValidateRequest is a middleware that validates the request body. s is a
+// pointer to a struct; the parsed value is stored in c.Locals("validated").
 func ValidateRequest(s interface{}) fiber.Handler {
 	v := NewValidator()
 
 	return func(c *fiber.Ctx) error {
-		// Parse request body into the provided struct
+		// Parse request body into a new value of the provided struct type
+		s := newLike(s)
 		if err := c.BodyParser(s); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Invalid request body",
@@ -58,12 +93,14 @@ func ValidateRequest(s interface{}) fiber.Handler {
 	}
 }
 
-// ValidateQueryParams validates query parameters
+// ValidateQueryParams validates query parameters. s is a pointer to a
+// struct; the parsed value is stored in c.Locals("queryParams").
 func ValidateQueryParams(s interface{}) fiber.Handler {
 	v := NewValidator()
 
 	return func(c *fiber.Ctx) error {
-		// Parse query parameters into the provided struct
+		// Parse query parameters into a new value of the provided struct type
+		s := newLike(s)
 		if err := c.QueryParser(s); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Invalid query parameters",
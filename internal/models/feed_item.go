@@ -1,11 +1,35 @@
 package models
 
+import "time"
+
 // FeedItem represents the Turkish source feed structure
 type FeedItem struct {
-	Guid      string `json:"guid"`
-	TitleTR   string `json:"title"`
-	ContentTR string `json:"content"`
-	Image     string `json:"image"`
-	Url       string `json:"url"`
-	Category  string `json:"category"`
+	Guid         string `json:"guid"`
+	TitleTR      string `json:"title"`
+	ContentTR    string `json:"content"`
+	Image        string `json:"image"`
+	Url          string `json:"url"`
+	CanonicalUrl string `json:"canonical_url,omitempty"`
+	Category     string `json:"category"`
+	Source       string `json:"source,omitempty"`
+
+	// CategorySlug is the taxonomy slug Category maps to for this source,
+	// empty if the label is not mapped
+	CategorySlug string `json:"category_slug,omitempty"`
+
+	// PublishedAt is the publication date given by the feed, if any
+	PublishedAt time.Time `json:"published_at,omitempty"`
+
+	// Metadata holds og:, twitter: and article: properties of the article page
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Flags marks quality issues found while parsing, e.g. "encoding"
+	Flags []string `json:"flags,omitempty"`
+
+	// ClaimToken identifies the dedup claim the item is processed under;
+	// it is never stored
+	ClaimToken string `json:"-"`
 }
+
+// FlagEncoding marks items whose content still shows encoding damage after transcoding
+const FlagEncoding = "encoding"
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Job statuses
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobPartial   = "partial" // finished, but some feeds or items failed
+	JobFailed    = "failed"
+)
+
+// Feed fetch statuses
+const (
+	FeedStatusOK      = "ok"
+	FeedStatusFailed  = "failed"
+	FeedStatusSkipped = "skipped"
+)
+
+// Job records one feed processing run
+type Job struct {
+	ID         string       `json:"id"`
+	Status     string       `json:"status"`
+	FeedURLs   []string     `json:"feed_urls"`
+	Feeds      []FeedStatus `json:"feeds,omitempty"`
+	Queued     int          `json:"items_queued"`
+	Processed  int          `json:"items_processed"`
+	Failed     int          `json:"items_failed"`
+	Flagged    int          `json:"items_flagged,omitempty"`  // processed, but with quality flags
+	Rejected   int          `json:"items_rejected,omitempty"` // dropped for good, e.g. for an unmapped category
+	Error      string       `json:"error,omitempty"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at,omitempty"`
+}
+
+// FeedStatus is the fetch outcome of one feed in a job
+type FeedStatus struct {
+	URL        string `json:"url"`
+	Status     string `json:"status"`
+	Items      int    `json:"items"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
@@ -2,10 +2,15 @@ package models
 
 import "time"
 
+// DefaultLanguage is the language news items are generated in
+const DefaultLanguage = "en"
+
 // NewsItem represents the generated English content
 type NewsItem struct {
 	ID           string    `json:"id"`
 	SourceGuid   string    `json:"source_guid"`
+	Source       string    `json:"source,omitempty"`
+	Language     string    `json:"language,omitempty"`
 	SeoTitle     string    `json:"seo_title"`
 	SeoDesc      string    `json:"seo_description"`
 	TLDR         []string  `json:"tldr"`
@@ -16,8 +21,44 @@ type NewsItem struct {
 	ImageTitle   string    `json:"image_title"`
 	ImageDesc    string    `json:"image_desc"`
 	OriginalUrl  string    `json:"original_url"`
+	CanonicalUrl string    `json:"canonical_url,omitempty"`
+	ClusterID    string    `json:"cluster_id,omitempty"`
 	FilePath     string    `json:"file_path,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	PublishedAt  time.Time `json:"published_at,omitempty"`
 	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+
+	// CategoryCandidate keeps a category that is not in the taxonomy while
+	// the item waits in the review bucket
+	CategoryCandidate string `json:"category_candidate,omitempty"`
+
+	// Flags carries the quality flags of the source item, e.g. FlagEncoding,
+	// so editors can check the generated text against the original
+	Flags []string `json:"flags,omitempty"`
+
+	// Model and PromptVersion record how the content was generated; Revision
+	// is the number of the stored revision it matches
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	Revision      int    `json:"revision,omitempty"`
+
+	// Status is the editorial state, one of the Status constants. The
+	// reason, editor and time of the last change of state are kept with it.
+	Status       string    `json:"status,omitempty"`
+	StatusReason string    `json:"status_reason,omitempty"`
+	StatusBy     string    `json:"status_by,omitempty"`
+	StatusAt     time.Time `json:"status_at,omitempty"`
+}
+
+// RelatedNews is a short summary of a news item belonging to the same story cluster
+type RelatedNews struct {
+	ID          string    `json:"id"`
+	ClusterID   string    `json:"cluster_id"`
+	SeoTitle    string    `json:"seo_title"`
+	SeoDesc     string    `json:"seo_description"`
+	Image       string    `json:"image"`
+	OriginalUrl string    `json:"original_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	Status      string    `json:"status,omitempty"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// EditorAI is the editor of revisions written by the generation pipeline
+const EditorAI = "ai"
+
+// Revision is a stored version of a news item. Number counts from 1 and
+// matches NewsItem.Revision of the version it holds.
+type Revision struct {
+	NewsID        string    `json:"news_id"`
+	Number        int       `json:"number"`
+	Model         string    `json:"model,omitempty"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Editor        string    `json:"editor"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	Content       *NewsItem `json:"content"`
+}
+
+// NewRevision snapshots item as its current revision
+func NewRevision(item *NewsItem, editor, note string) *Revision {
+	content := *item
+	content.FilePath = ""
+	return &Revision{
+		NewsID:        item.ID,
+		Number:        item.Revision,
+		Model:         item.Model,
+		PromptVersion: item.PromptVersion,
+		Editor:        editor,
+		Note:          note,
+		CreatedAt:     time.Now(),
+		Content:       &content,
+	}
+}
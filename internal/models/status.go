@@ -0,0 +1,56 @@
+package models
+
+// Editorial states of a news item. Only published items are shown by the
+// public API.
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusRejected  = "rejected"
+)
+
+// transitions lists the states each state may move to. Published items
+// go back to review or are rejected to take them down; rejected items are
+// reworked as drafts or reviewed again before they can be published.
+var transitions = map[string][]string{
+	StatusDraft:     {StatusInReview, StatusPublished, StatusRejected},
+	StatusInReview:  {StatusDraft, StatusPublished, StatusRejected},
+	StatusPublished: {StatusInReview, StatusRejected},
+	StatusRejected:  {StatusDraft, StatusInReview},
+}
+
+// ValidStatus reports whether status is one of the editorial states
+func ValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// Transitions returns the states an item in state from may move to
+func Transitions(from string) []string {
+	return append([]string{}, transitions[from]...)
+}
+
+// CanTransition reports whether an item may move from one state to another
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentStatus returns the editorial state of the item. Items stored
+// before the workflow existed have none and were public, so they count as
+// published.
+func (n *NewsItem) CurrentStatus() string {
+	if n.Status == "" {
+		return StatusPublished
+	}
+	return n.Status
+}
+
+// IsPublished reports whether the public API may show the item
+func (n *NewsItem) IsPublished() bool {
+	return n.CurrentStatus() == StatusPublished
+}
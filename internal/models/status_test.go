@@ -0,0 +1,40 @@
+package models
+
+import "testing"
+
+func TestStatusTransitions(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusInReview, StatusPublished, true},
+		{StatusInReview, StatusRejected, true},
+		{StatusDraft, StatusInReview, true},
+		{StatusPublished, StatusInReview, true},
+		{StatusRejected, StatusPublished, false},
+		{StatusRejected, StatusDraft, true},
+		{StatusPublished, StatusDraft, false},
+		{StatusPublished, "archived", false},
+	}
+	for _, tc := range cases {
+		if got := CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("Expected CanTransition(%q, %q) to be %v, got %v", tc.from, tc.to, tc.want, got)
+		}
+	}
+
+	if ValidStatus("archived") {
+		t.Errorf("Expected archived not to be a valid status")
+	}
+}
+
+func TestCurrentStatus(t *testing.T) {
+	legacy := &NewsItem{ID: "1"}
+	if !legacy.IsPublished() {
+		t.Errorf("Expected an item without status to count as published, got %q", legacy.CurrentStatus())
+	}
+
+	review := &NewsItem{ID: "2", Status: StatusInReview}
+	if review.IsPublished() {
+		t.Errorf("Expected an item in review not to be published")
+	}
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	unicodetokenizer "github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/registry"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldedAnalyzer splits text into words and folds them, so queries match
+// regardless of case and diacritics
+const foldedAnalyzer = "folded"
+
+const foldFilter = "fold"
+
+func init() {
+	registry.RegisterTokenFilter(foldFilter, func(map[string]interface{}, *registry.Cache) (analysis.TokenFilter, error) {
+		return foldingFilter{}, nil
+	})
+}
+
+// addFoldedAnalyzer registers the folded analyzer with m
+func addFoldedAnalyzer(m *mapping.IndexMappingImpl) error {
+	return m.AddCustomAnalyzer(foldedAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicodetokenizer.Name,
+		"token_filters": []string{foldFilter},
+	})
+}
+
+// foldingFilter folds every token in place. Token offsets are kept, so
+// highlights mark the original text.
+type foldingFilter struct{}
+
+func (foldingFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		token.Term = []byte(fold(string(token.Term)))
+	}
+	return input
+}
+
+// fold lowercases a word and strips diacritics, so "Erdoğan" matches
+// "erdogan" and "İstanbul" matches "istanbul"
+func fold(word string) string {
+	var b strings.Builder
+	b.Grow(len(word))
+	for _, r := range norm.NFD.String(word) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'ı':
+			r = 'i'
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,304 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// document is the indexed form of one news item. The JSON names are the
+// field names of the index.
+type document struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TLDR        string    `json:"tldr"`
+	Content     string    `json:"content"`
+	Tags        string    `json:"tags"`
+	Category    string    `json:"category"`
+	Source      string    `json:"source"`
+	Status      string    `json:"status"`
+	Created     time.Time `json:"created"`
+	Stamp       string    `json:"stamp"` // version of the item the document was built from
+}
+
+// newDocument returns the indexed form of a news item
+func newDocument(item *models.NewsItem) *document {
+	return &document{
+		Title:       item.SeoTitle,
+		Description: item.SeoDesc,
+		TLDR:        strings.Join(item.TLDR, "\n"),
+		Content:     item.ContentMD,
+		Tags:        strings.Join(item.Tags, ", "),
+		Category:    item.Category,
+		Source:      item.Source,
+		Status:      item.CurrentStatus(),
+		Created:     item.CreatedAt,
+		Stamp:       stampOf(item),
+	}
+}
+
+// stampOf identifies the version of an item, so Sync can tell which
+// documents are out of date
+func stampOf(item *models.NewsItem) string {
+	return fmt.Sprintf("%d/%s/%s", item.Revision, item.UpdatedAt.UTC().Format(time.RFC3339Nano), item.CurrentStatus())
+}
+
+// newMapping maps the text fields of documents with the folded analyzer and
+// keeps them for highlighting. Filters match their values exactly.
+func newMapping() (mapping.IndexMapping, error) {
+	m := bleve.NewIndexMapping()
+	if err := addFoldedAnalyzer(m); err != nil {
+		return nil, err
+	}
+
+	doc := bleve.NewDocumentStaticMapping()
+	for _, name := range fieldNames {
+		f := bleve.NewTextFieldMapping()
+		f.Analyzer = foldedAnalyzer
+		f.IncludeTermVectors = true
+		doc.AddFieldMappingsAt(name, f)
+	}
+	for _, name := range []string{"category", "source", "status"} {
+		f := bleve.NewKeywordFieldMapping()
+		f.Analyzer = keyword.Name
+		f.Store = false
+		doc.AddFieldMappingsAt(name, f)
+	}
+	doc.AddFieldMappingsAt("created", bleve.NewDateTimeFieldMapping())
+	stamp := bleve.NewKeywordFieldMapping()
+	stamp.Index = false
+	doc.AddFieldMappingsAt("stamp", stamp)
+
+	m.DefaultMapping = doc
+	return m, nil
+}
+
+// Hit is a search result
+type Hit struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+	// Highlights holds excerpts of the matching fields with matches wrapped
+	// in <mark> tags, keyed by field name
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// Results is a page of search results
+type Results struct {
+	Total int   `json:"total"`
+	Hits  []Hit `json:"hits"`
+}
+
+// Index is a Bleve full-text index of news items. NewIndex keeps it in
+// memory; OpenIndex keeps it on disk, so it survives restarts without
+// reading every news item again.
+type Index struct {
+	mu    sync.Mutex // serializes writes, so Sync cannot overwrite a newer Add
+	index bleve.Index
+}
+
+// NewIndex returns an empty index kept in memory
+func NewIndex() *Index {
+	m, err := newMapping()
+	if err != nil {
+		panic(err)
+	}
+	index, err := bleve.NewMemOnly(m)
+	if err != nil {
+		panic(err)
+	}
+	return &Index{index: index}
+}
+
+// OpenIndex opens or creates the index directory at path. After a restart
+// only items that changed in the meantime need to be indexed again, see
+// Sync. It fails quickly when another process holds the index open.
+func OpenIndex(path string) (*Index, error) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		// The single-file index of earlier versions; the first Sync rebuilds it
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove old search index %s: %w", path, err)
+		}
+		logger.Get().Info().Str("path", path).Msg("Replacing search index of an earlier version")
+	}
+
+	index, err := bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": "1s"})
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create search index directory: %w", err)
+		}
+		var m mapping.IndexMapping
+		if m, err = newMapping(); err == nil {
+			index, err = bleve.New(path, m)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index %s: %w", path, err)
+	}
+	return &Index{index: index}, nil
+}
+
+// loadBatch is the number of documents Load writes at a time
+const loadBatch = 200
+
+// Load indexes existing news items
+func (x *Index) Load(items []*models.NewsItem) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.write(items, nil); err != nil {
+		logger.Get().Error().Err(err).Int("items", len(items)).Msg("Failed to index news items")
+	}
+}
+
+// write indexes items and removes the documents of ids in batches. It must
+// be called with the lock held.
+func (x *Index) write(items []*models.NewsItem, ids []string) error {
+	for i := 0; i < len(items) || i < len(ids); i += loadBatch {
+		batch := x.index.NewBatch()
+		for _, item := range items[min(i, len(items)):min(i+loadBatch, len(items))] {
+			if err := batch.Index(item.ID, newDocument(item)); err != nil {
+				return err
+			}
+		}
+		for _, id := range ids[min(i, len(ids)):min(i+loadBatch, len(ids))] {
+			batch.Delete(id)
+		}
+		if err := x.index.Batch(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync brings the index in line with items, the complete list of stored
+// news: it indexes items that are new or changed since they were indexed
+// and drops documents of items that are gone. It returns the number of
+// documents it added, replaced or dropped. The stamps are compared and the
+// documents written under one lock, so an Add cannot slip in between.
+func (x *Index) Sync(items []*models.NewsItem) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	stamps, err := x.stamps()
+	if err != nil {
+		return 0, err
+	}
+	var changed []*models.NewsItem
+	for _, item := range items {
+		if stamp, ok := stamps[item.ID]; !ok || stamp != stampOf(item) {
+			changed = append(changed, item)
+		}
+		delete(stamps, item.ID)
+	}
+	gone := make([]string, 0, len(stamps))
+	for id := range stamps {
+		gone = append(gone, id)
+	}
+
+	if err := x.write(changed, gone); err != nil {
+		return 0, fmt.Errorf("failed to update search index: %w", err)
+	}
+	return len(changed) + len(gone), nil
+}
+
+// stamps returns the stamp of every indexed document by ID
+func (x *Index) stamps() (map[string]string, error) {
+	n, err := x.index.DocCount()
+	if err != nil {
+		return nil, err
+	}
+	req := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(n), 0, false)
+	req.Fields = []string{"stamp"}
+	res, err := x.index.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+	stamps := make(map[string]string, len(res.Hits))
+	for _, hit := range res.Hits {
+		stamp, _ := hit.Fields["stamp"].(string)
+		stamps[hit.ID] = stamp
+	}
+	return stamps, nil
+}
+
+// Add indexes a news item, replacing an earlier version with the same ID
+func (x *Index) Add(item *models.NewsItem) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.index.Index(item.ID, newDocument(item)); err != nil {
+		logger.Get().Error().Err(err).Str("id", item.ID).Msg("Failed to index news item")
+	}
+}
+
+// Remove drops a news item from the index
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.index.Delete(id); err != nil {
+		logger.Get().Error().Err(err).Str("id", id).Msg("Failed to remove news item from the index")
+	}
+}
+
+// Len returns the number of indexed items
+func (x *Index) Len() int {
+	n, err := x.index.DocCount()
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+// Close releases the index files of an index opened with OpenIndex
+func (x *Index) Close() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.index.Close()
+}
+
+// Search returns the hits of the given page, best first and newest first
+// among equal scores
+func (x *Index) Search(q Query, offset, limit int) (Results, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = x.Len()
+	}
+	req := bleve.NewSearchRequestOptions(q.bleveQuery(), limit, offset, false)
+	req.SortBy([]string{"-_score", "-created"})
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	req.Highlight.Fields = fieldNames[:]
+
+	res, err := x.index.Search(req)
+	if err != nil {
+		return Results{Hits: []Hit{}}, fmt.Errorf("failed to search index: %w", err)
+	}
+	results := Results{Total: int(res.Total), Hits: make([]Hit, 0, len(res.Hits))}
+	for _, h := range res.Hits {
+		hit := Hit{ID: h.ID, Score: math.Round(h.Score*1000) / 1000}
+		for field, fragments := range h.Fragments {
+			// Fields without a match come back unmarked
+			if len(fragments) == 0 || !strings.Contains(fragments[0], "<mark>") {
+				continue
+			}
+			if hit.Highlights == nil {
+				hit.Highlights = make(map[string]string)
+			}
+			hit.Highlights[field] = fragments[0]
+		}
+		results.Hits = append(results.Hits, hit)
+	}
+	return results, nil
+}
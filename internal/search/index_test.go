@@ -0,0 +1,242 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// testItems are the fixtures of the index tests
+func testItems() []*models.NewsItem {
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	return []*models.NewsItem{
+		{
+			ID:        "1",
+			SeoTitle:  "Central bank holds interest rates",
+			SeoDesc:   "The central bank kept its policy rate at 45 percent.",
+			ContentMD: "Governor Karahan said inflation is slowing. Rates will stay high.",
+			Category:  "economy",
+			Tags:      []string{"inflation", "central bank"},
+			CreatedAt: base,
+		},
+		{
+			ID:        "2",
+			SeoTitle:  "Lira slides as inflation data disappoints",
+			SeoDesc:   "Markets reacted to a higher than expected inflation figure.",
+			ContentMD: "The bank of the central district was not involved.",
+			Category:  "finance",
+			Tags:      []string{"lira", "markets"},
+			CreatedAt: base.Add(time.Hour),
+		},
+		{
+			ID:        "3",
+			SeoTitle:  "Galatasaray wins the derby in İstanbul",
+			SeoDesc:   "A late goal settled the match.",
+			ContentMD: "Fans celebrated in Beşiktaş and Kadıköy.",
+			Category:  "sports",
+			Tags:      []string{"football"},
+			CreatedAt: base.Add(2 * time.Hour),
+		},
+	}
+}
+
+// forEachStore runs fn on an index of the fixtures kept in memory and on
+// one kept in a file
+func forEachStore(t *testing.T, fn func(t *testing.T, x *Index)) {
+	t.Run("memory", func(t *testing.T) {
+		x := NewIndex()
+		x.Load(testItems())
+		fn(t, x)
+	})
+	t.Run("file", func(t *testing.T) {
+		x, err := OpenIndex(filepath.Join(t.TempDir(), "search.db"))
+		if err != nil {
+			t.Fatalf("OpenIndex failed: %v", err)
+		}
+		defer x.Close()
+		x.Load(testItems())
+		fn(t, x)
+	})
+}
+
+func search(t *testing.T, x *Index, q string) Results {
+	t.Helper()
+	query, err := ParseQuery(q)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) failed: %v", q, err)
+	}
+	return mustSearch(t, x, query)
+}
+
+func mustSearch(t *testing.T, x *Index, query Query) Results {
+	t.Helper()
+	results, err := x.Search(query, 0, 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return results
+}
+
+func ids(r Results) string {
+	var out []string
+	for _, h := range r.Hits {
+		out = append(out, h.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSearchRanking(t *testing.T) {
+	forEachStore(t, func(t *testing.T, x *Index) {
+
+		// Both mention inflation; the title and tag match of 2 and 1 outrank body text
+		if got := ids(search(t, x, "inflation")); got != "1,2" && got != "2,1" {
+			t.Errorf("Expected items 1 and 2, got %s", got)
+		}
+		if got := ids(search(t, x, "title:inflation")); got != "2" {
+			t.Errorf("Expected only item 2 for a title match, got %s", got)
+		}
+
+		// The phrase matches item 1; item 2 has both words but not next to each other
+		if got := ids(search(t, x, `"central bank"`)); got != "1" {
+			t.Errorf("Expected the phrase to match item 1 only, got %s", got)
+		}
+		if got := ids(search(t, x, "central bank")); got != "1,2" {
+			t.Errorf("Expected item 1 to outrank item 2, got %s", got)
+		}
+
+		if got := ids(search(t, x, "inflation -lira")); got != "1" {
+			t.Errorf("Expected the exclusion to drop item 2, got %s", got)
+		}
+		if got := ids(search(t, x, "inflation category:finance")); got != "2" {
+			t.Errorf("Expected the category filter to keep item 2, got %s", got)
+		}
+		// Diacritics and Turkish dotted capitals are folded
+		if got := ids(search(t, x, "istanbul kadikoy")); got != "3" {
+			t.Errorf("Expected folded matches for item 3, got %s", got)
+		}
+	})
+}
+
+func TestSearchHighlights(t *testing.T) {
+	forEachStore(t, func(t *testing.T, x *Index) {
+		// Fields are highlighted where a clause matches them: the phrase in
+		// the title, the word in the title and the content
+		r := search(t, x, `"interest rates" rates`)
+		if len(r.Hits) != 1 {
+			t.Fatalf("Expected one hit, got %d", len(r.Hits))
+		}
+		h := r.Hits[0].Highlights
+		if h["title"] != "Central bank holds <mark>interest</mark> <mark>rates</mark>" {
+			t.Errorf("Unexpected title highlight: %q", h["title"])
+		}
+		if !strings.Contains(h["content"], "<mark>Rates</mark> will stay high") {
+			t.Errorf("Unexpected content highlight: %q", h["content"])
+		}
+		if _, ok := h["tags"]; ok {
+			t.Errorf("Expected no highlight for fields without a match, got %v", h)
+		}
+	})
+}
+
+func TestSearchUpdateAndRemove(t *testing.T) {
+	forEachStore(t, func(t *testing.T, x *Index) {
+		x.Add(&models.NewsItem{ID: "2", SeoTitle: "Lira recovers", Category: "finance"})
+		if got := ids(search(t, x, "inflation")); got != "1" {
+			t.Errorf("Expected the replaced item to lose its old terms, got %s", got)
+		}
+		x.Remove("1")
+		if got := ids(search(t, x, "inflation")); got != "" {
+			t.Errorf("Expected no hits after removal, got %s", got)
+		}
+		if x.Len() != 2 {
+			t.Errorf("Expected 2 indexed items, got %d", x.Len())
+		}
+	})
+}
+
+func TestSearchStatusFilter(t *testing.T) {
+	forEachStore(t, func(t *testing.T, x *Index) {
+		x.Add(&models.NewsItem{ID: "4", SeoTitle: "Inflation report delayed", Status: models.StatusInReview})
+
+		query, err := ParseQuery("inflation")
+		if err != nil {
+			t.Fatalf("ParseQuery failed: %v", err)
+		}
+		query.Status = models.StatusPublished
+		if got := ids(mustSearch(t, x, query)); got != "1,2" && got != "2,1" {
+			t.Errorf("Expected only published items, got %s", got)
+		}
+		query.Status = models.StatusInReview
+		if got := ids(mustSearch(t, x, query)); got != "4" {
+			t.Errorf("Expected the item in review, got %s", got)
+		}
+	})
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, q := range []string{"", "-lira", "category:economy", `"central bank`} {
+		if _, err := ParseQuery(q); err == nil {
+			t.Errorf("Expected %q to be rejected", q)
+		}
+	}
+}
+
+func TestIndexFileSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.db")
+	x, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("OpenIndex failed: %v", err)
+	}
+	items := testItems()
+	if n, err := x.Sync(items); err != nil || n != 3 {
+		t.Fatalf("Expected the first sync to index 3 items, got %d, err %v", n, err)
+	}
+	x.Close()
+
+	x, err = OpenIndex(path)
+	if err != nil {
+		t.Fatalf("OpenIndex failed: %v", err)
+	}
+	defer x.Close()
+	if x.Len() != 3 {
+		t.Fatalf("Expected 3 documents after reopening, got %d", x.Len())
+	}
+	if got := ids(search(t, x, `"central bank"`)); got != "1" {
+		t.Errorf("Expected the reopened index to find item 1, got %s", got)
+	}
+
+	// Only the edited item and the deleted one are synced again
+	items[1].SeoTitle = "Lira recovers"
+	items[1].Revision = 2
+	if n, err := x.Sync(items[:2]); err != nil || n != 2 {
+		t.Fatalf("Expected the sync to change 2 documents, got %d, err %v", n, err)
+	}
+	if got := ids(search(t, x, "title:lira")); got != "2" {
+		t.Errorf("Expected the edited title to be indexed, got %s", got)
+	}
+	if got := ids(search(t, x, "galatasaray")); got != "" {
+		t.Errorf("Expected the deleted item to be dropped, got %s", got)
+	}
+	if n, err := x.Sync(items[:2]); err != nil || n != 0 {
+		t.Errorf("Expected nothing to sync for an index in line, got %d, err %v", n, err)
+	}
+}
+
+func TestOpenIndexReplacesOldIndexFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.db")
+	if err := os.WriteFile(path, []byte("bbolt"), 0644); err != nil {
+		t.Fatalf("Failed to write old index file: %v", err)
+	}
+	x, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Expected the old index file to be replaced, got %v", err)
+	}
+	defer x.Close()
+	if n, err := x.Sync(testItems()); err != nil || n != 3 {
+		t.Errorf("Expected the first sync to index 3 items, got %d, err %v", n, err)
+	}
+}
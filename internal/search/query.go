@@ -0,0 +1,158 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// Searchable fields of a news item
+const (
+	FieldTitle = iota
+	FieldDescription
+	FieldTLDR
+	FieldContent
+	FieldTags
+	numFields
+)
+
+// fieldNames are the names used in queries and highlights
+var fieldNames = [numFields]string{"title", "description", "tldr", "content", "tags"}
+
+// fieldWeights rank matches in titles and tags above matches in the body
+var fieldWeights = [numFields]float64{3, 2, 1.5, 1, 2.5}
+
+// fieldAliases maps query prefixes to fields
+var fieldAliases = map[string]int{
+	"title": FieldTitle, "description": FieldDescription, "desc": FieldDescription,
+	"tldr": FieldTLDR, "content": FieldContent, "body": FieldContent,
+	"tags": FieldTags, "tag": FieldTags,
+}
+
+// clause is one part of a query: a word or phrase, optionally restricted to
+// one field. Clauses with several words match only as a phrase.
+type clause struct {
+	text   string
+	field  int // -1 for any field
+	negate bool
+}
+
+// Query is a parsed search query. All clauses must match, negated ones
+// must not; Category filters on the taxonomy slug. Status filters on the
+// editorial state and is set by the caller, never parsed from the query.
+type Query struct {
+	clauses  []clause
+	Category string
+	Source   string
+	Status   string
+}
+
+// ParseQuery parses a query such as
+//
+//	inflation "central bank" title:lira -sports category:economy
+func ParseQuery(q string) (Query, error) {
+	var query Query
+	rest := strings.TrimSpace(q)
+	for rest != "" {
+		negate := false
+		if rest[0] == '-' {
+			negate = true
+			rest = rest[1:]
+		}
+
+		field := -1
+		filter := ""
+		if i := strings.IndexByte(rest, ':'); i > 0 && !strings.ContainsFunc(rest[:i], unicode.IsSpace) && !strings.Contains(rest[:i], `"`) {
+			name := strings.ToLower(rest[:i])
+			if f, ok := fieldAliases[name]; ok {
+				field = f
+				rest = rest[i+1:]
+			} else if name == "category" || name == "source" {
+				filter = name
+				rest = rest[i+1:]
+			}
+		}
+
+		var text string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return Query{}, fmt.Errorf("unterminated phrase in query")
+			}
+			text, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			text, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimSpace(rest)
+
+		switch filter {
+		case "category":
+			query.Category = strings.ToLower(strings.TrimSpace(text))
+			continue
+		case "source":
+			query.Source = strings.TrimSpace(text)
+			continue
+		}
+		if strings.ContainsFunc(text, isWordRune) {
+			query.clauses = append(query.clauses, clause{text: text, field: field, negate: negate})
+		}
+	}
+
+	for _, c := range query.clauses {
+		if !c.negate {
+			return query, nil
+		}
+	}
+	return Query{}, fmt.Errorf("query has no search terms")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// bleveQuery returns the query for the index
+func (q Query) bleveQuery() query.Query {
+	b := bleve.NewBooleanQuery()
+	for _, c := range q.clauses {
+		if c.negate {
+			b.AddMustNot(c.query())
+		} else {
+			b.AddMust(c.query())
+		}
+	}
+	for field, value := range map[string]string{"category": q.Category, "source": q.Source, "status": q.Status} {
+		if value != "" {
+			term := bleve.NewTermQuery(value)
+			term.SetField(field)
+			b.AddMust(term)
+		}
+	}
+	return b
+}
+
+// query matches the clause in its field, or in any field weighted by
+// fieldWeights
+func (c clause) query() query.Query {
+	if c.field >= 0 {
+		return c.fieldQuery(c.field)
+	}
+	d := bleve.NewDisjunctionQuery()
+	for f := 0; f < numFields; f++ {
+		d.AddQuery(c.fieldQuery(f))
+	}
+	return d
+}
+
+func (c clause) fieldQuery(f int) query.Query {
+	q := bleve.NewMatchPhraseQuery(c.text)
+	q.SetField(fieldNames[f])
+	q.SetBoost(fieldWeights[f])
+	return q
+}
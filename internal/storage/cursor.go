@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// Cursor is a position in a list of news items: the sort key and ID of the
+// item a page starts after. Unlike offsets, cursors stay valid while new
+// items arrive.
+type Cursor struct {
+	// Sort and Ascending are the order the cursor was taken in
+	Sort      string
+	Ascending bool
+	Time      time.Time
+	ID        string
+	// Backward marks a cursor that pages towards the start of the list
+	Backward bool
+}
+
+// cursorToken is the encoded form of a Cursor
+type cursorToken struct {
+	Sort      string `json:"s,omitempty"`
+	Ascending bool   `json:"a,omitempty"`
+	Time      int64  `json:"t"`
+	ID        string `json:"id"`
+	Backward  bool   `json:"b,omitempty"`
+}
+
+// CursorAt returns the cursor positioned at item in the order of q
+func CursorAt(item *models.NewsItem, q Query) Cursor {
+	return Cursor{
+		Sort:      q.sort(),
+		Ascending: q.Ascending,
+		Time:      q.sortKey(MetaOf(item, "")),
+		ID:        item.ID,
+	}
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(cursorToken{
+		Sort:      c.Sort,
+		Ascending: c.Ascending,
+		Time:      c.Time.UnixNano(),
+		ID:        c.ID,
+		Backward:  c.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token returned by Encode
+func DecodeCursor(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor")
+	}
+	var t cursorToken
+	if err := json.Unmarshal(data, &t); err != nil || t.ID == "" {
+		return Cursor{}, fmt.Errorf("invalid cursor")
+	}
+	if t.Sort != SortCreated && t.Sort != SortPublished {
+		return Cursor{}, fmt.Errorf("invalid cursor")
+	}
+	return Cursor{
+		Sort:      t.Sort,
+		Ascending: t.Ascending,
+		Time:      time.Unix(0, t.Time).UTC(),
+		ID:        t.ID,
+		Backward:  t.Backward,
+	}, nil
+}
+
+// Matches reports whether the cursor was taken in the order of q
+func (c Cursor) Matches(q Query) bool {
+	return c.Sort == q.sort() && c.Ascending == q.Ascending
+}
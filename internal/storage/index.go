@@ -0,0 +1,467 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// Index buckets. Items holds the metadata of each news item by ID; the
+// order buckets order IDs by creation time, overall and per category.
+// Counts keeps the number of items per status and category.
+var (
+	bucketItems      = []byte("items")
+	bucketByCreated  = []byte("by_created")
+	bucketByCategory = []byte("by_category")
+	bucketCounts     = []byte("counts")
+	bucketInfo       = []byte("info")
+)
+
+// indexBuckets are the buckets emptied when the index is rebuilt
+var indexBuckets = [][]byte{bucketItems, bucketByCreated, bucketByCategory, bucketCounts}
+
+// indexVersion is bumped whenever Meta gains fields that queries filter on
+// or the buckets change. An index of another version is emptied on open so
+// it is rebuilt.
+const indexVersion = "4"
+
+var keyVersion = []byte("version")
+
+// Meta is the indexed metadata of a stored news item
+type Meta struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	CreatedAt   time.Time `json:"created_at"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	HasImage    bool      `json:"has_image,omitempty"`
+	Status      string    `json:"status,omitempty"`
+}
+
+// MetaOf returns the metadata of a news item stored at path
+func MetaOf(item *models.NewsItem, path string) Meta {
+	return Meta{
+		ID:          item.ID,
+		Path:        path,
+		CreatedAt:   item.CreatedAt,
+		PublishedAt: item.PublishedAt,
+		Category:    item.Category,
+		Tags:        item.Tags,
+		Source:      item.Source,
+		Language:    item.Language,
+		HasImage:    item.Image != "",
+		Status:      item.Status,
+	}
+}
+
+// published returns the publication time, or the creation time of items
+// that were never given one
+func (m Meta) published() time.Time {
+	if m.PublishedAt.IsZero() {
+		return m.CreatedAt
+	}
+	return m.PublishedAt
+}
+
+// language returns the language of the item; items stored before the field
+// existed are English
+func (m Meta) language() string {
+	if m.Language == "" {
+		return models.DefaultLanguage
+	}
+	return m.Language
+}
+
+// status returns the editorial state of the item; items stored before the
+// workflow existed are published
+func (m Meta) status() string {
+	if m.Status == "" {
+		return models.StatusPublished
+	}
+	return m.Status
+}
+
+// Index is an embedded bbolt index of news item metadata. Lookups by ID and
+// pages ordered by creation time take O(log n) instead of a directory walk.
+type Index struct {
+	db *bolt.DB
+}
+
+// OpenIndex opens or creates the index file. It fails quickly when another
+// process holds the file open.
+func OpenIndex(path string) (*Index, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		info, err := tx.CreateBucketIfNotExists(bucketInfo)
+		if err != nil {
+			return err
+		}
+		if string(info.Get(keyVersion)) != indexVersion {
+			if err := resetBuckets(tx); err != nil {
+				return err
+			}
+			return info.Put(keyVersion, []byte(indexVersion))
+		}
+		for _, name := range indexBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize index: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the index file
+func (x *Index) Close() error {
+	return x.db.Close()
+}
+
+// Len returns the number of indexed items
+func (x *Index) Len() (int, error) {
+	var n int
+	err := x.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(bucketItems).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// Get returns the metadata of the item with the given ID
+func (x *Index) Get(id string) (Meta, error) {
+	var meta Meta
+	err := x.db.View(func(tx *bolt.Tx) error {
+		var err error
+		meta, err = getMeta(tx, id)
+		return err
+	})
+	return meta, err
+}
+
+// Put adds or replaces the metadata of an item
+func (x *Index) Put(meta Meta) error {
+	return x.db.Update(func(tx *bolt.Tx) error {
+		return putMeta(tx, meta)
+	})
+}
+
+// Delete removes an item from the index
+func (x *Index) Delete(id string) error {
+	return x.db.Update(func(tx *bolt.Tx) error {
+		return deleteMeta(tx, id)
+	})
+}
+
+// List returns the metadata of the query's page in the query's order.
+// Pages by creation time walk the order buckets and stop once the page is
+// full; pages by publication time sort every matching entry.
+func (x *Index) List(q Query) ([]Meta, error) {
+	if q.Sort == SortPublished {
+		return x.listSorted(q)
+	}
+
+	bucket, prefix := bucketByCreated, []byte(nil)
+	if q.Category != "" {
+		bucket, prefix = bucketByCategory, categoryPrefix(q.Category)
+	}
+
+	metas := []Meta{}
+	err := x.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		first, next := lastWithPrefix, c.Prev
+		if q.Ascending {
+			first, next = firstWithPrefix, c.Next
+		}
+		if q.After != nil {
+			// Seek straight to the cursor instead of walking up to it
+			first = func(c *bolt.Cursor, prefix []byte) ([]byte, []byte) {
+				return seekAfter(c, orderKey(prefix, Meta{ID: q.After.ID, CreatedAt: q.After.Time}), q.Ascending)
+			}
+		}
+		skip := q.Offset
+		for k, v := first(c, prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = next() {
+			meta, err := getMeta(tx, string(v))
+			if err != nil {
+				return err
+			}
+			if pastRange(meta.CreatedAt, q.CreatedFrom, q.CreatedTo, q.Ascending) {
+				break
+			}
+			if !q.MatchMeta(meta) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			metas = append(metas, meta)
+			if q.Limit > 0 && len(metas) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return metas, err
+}
+
+// listSorted filters and sorts every entry before paging
+func (x *Index) listSorted(q Query) ([]Meta, error) {
+	var metas []Meta
+	err := x.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketItems).ForEach(func(k, v []byte) error {
+			var meta Meta
+			if err := json.Unmarshal(v, &meta); err != nil {
+				return fmt.Errorf("corrupt index entry %s: %w", k, err)
+			}
+			if q.MatchMeta(meta) && q.beyond(meta) {
+				metas = append(metas, meta)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(metas, func(i, j int) bool { return q.less(metas[i], metas[j]) })
+	start, end := pageBounds(q, len(metas))
+	return append([]Meta{}, metas[start:end]...), nil
+}
+
+// Count returns the number of entries matching the query's filters.
+// Counts by status and category are kept up to date; other filters walk
+// every entry.
+func (x *Index) Count(q Query) (int, error) {
+	q = q.counted()
+	if !q.filtered() {
+		return x.Len()
+	}
+	n := 0
+	rest := q
+	rest.Status, rest.Category = "", ""
+	if !rest.filtered() {
+		err := x.db.View(func(tx *bolt.Tx) error {
+			n = getCount(tx, countKey(q.Status, q.Category))
+			return nil
+		})
+		return n, err
+	}
+	err := x.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketItems).ForEach(func(k, v []byte) error {
+			var meta Meta
+			if err := json.Unmarshal(v, &meta); err != nil {
+				return fmt.Errorf("corrupt index entry %s: %w", k, err)
+			}
+			if q.MatchMeta(meta) {
+				n++
+			}
+			return nil
+		})
+	})
+	return n, err
+}
+
+// pastRange reports whether a walk in creation order has left [from, to)
+func pastRange(t, from, to time.Time, ascending bool) bool {
+	if ascending {
+		return !to.IsZero() && !t.Before(to)
+	}
+	return !from.IsZero() && t.Before(from)
+}
+
+// Reset replaces the whole index with the given metadata
+func (x *Index) Reset(metas []Meta) error {
+	return x.db.Update(func(tx *bolt.Tx) error {
+		if err := resetBuckets(tx); err != nil {
+			return err
+		}
+		for _, meta := range metas {
+			if err := putMeta(tx, meta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// resetBuckets empties the item and order buckets
+func resetBuckets(tx *bolt.Tx) error {
+	for _, name := range indexBuckets {
+		if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getMeta(tx *bolt.Tx, id string) (Meta, error) {
+	var meta Meta
+	data := tx.Bucket(bucketItems).Get([]byte(id))
+	if data == nil {
+		return meta, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("corrupt index entry %s: %w", id, err)
+	}
+	return meta, nil
+}
+
+func putMeta(tx *bolt.Tx, meta Meta) error {
+	if meta.ID == "" {
+		return fmt.Errorf("cannot index an item without ID")
+	}
+	// Drop the order keys of the previous version
+	if err := deleteMeta(tx, meta.ID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketItems).Put([]byte(meta.ID), data); err != nil {
+		return err
+	}
+	id := []byte(meta.ID)
+	if err := tx.Bucket(bucketByCreated).Put(orderKey(nil, meta), id); err != nil {
+		return err
+	}
+	if meta.Category != "" {
+		if err := tx.Bucket(bucketByCategory).Put(orderKey(categoryPrefix(meta.Category), meta), id); err != nil {
+			return err
+		}
+	}
+	return addCounts(tx, meta, 1)
+}
+
+func deleteMeta(tx *bolt.Tx, id string) error {
+	meta, err := getMeta(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketByCreated).Delete(orderKey(nil, meta)); err != nil {
+		return err
+	}
+	if meta.Category != "" {
+		if err := tx.Bucket(bucketByCategory).Delete(orderKey(categoryPrefix(meta.Category), meta)); err != nil {
+			return err
+		}
+	}
+	if err := addCounts(tx, meta, -1); err != nil {
+		return err
+	}
+	return tx.Bucket(bucketItems).Delete([]byte(id))
+}
+
+// countKey names the counter of items with a status and category; an empty
+// value counts every status or category
+func countKey(status, category string) []byte {
+	return []byte(status + "\x00" + category)
+}
+
+// addCounts adds delta to the counters an entry is counted in
+func addCounts(tx *bolt.Tx, meta Meta, delta int) error {
+	keys := [][]byte{countKey(meta.status(), "")}
+	if meta.Category != "" {
+		keys = append(keys, countKey("", meta.Category), countKey(meta.status(), meta.Category))
+	}
+	b := tx.Bucket(bucketCounts)
+	for _, key := range keys {
+		n := getCount(tx, key) + delta
+		var err error
+		if n > 0 {
+			err = b.Put(key, binary.BigEndian.AppendUint64(nil, uint64(n)))
+		} else {
+			err = b.Delete(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getCount reads a counter; missing counters are zero
+func getCount(tx *bolt.Tx, key []byte) int {
+	v := tx.Bucket(bucketCounts).Get(key)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
+
+// orderKey sorts by creation time, then ID: prefix | big-endian time | id
+func orderKey(prefix []byte, meta Meta) []byte {
+	key := make([]byte, 0, len(prefix)+8+len(meta.ID))
+	key = append(key, prefix...)
+	// Flip the sign bit so times before 1970 sort first
+	key = binary.BigEndian.AppendUint64(key, uint64(meta.CreatedAt.UnixNano())^(1<<63))
+	return append(key, meta.ID...)
+}
+
+func categoryPrefix(category string) []byte {
+	return append([]byte(category), 0)
+}
+
+// firstWithPrefix positions the cursor on the first key starting with prefix
+func firstWithPrefix(c *bolt.Cursor, prefix []byte) ([]byte, []byte) {
+	if len(prefix) == 0 {
+		return c.First()
+	}
+	return c.Seek(prefix)
+}
+
+// seekAfter positions the cursor on the first key after key in the walk's
+// direction, whether or not key itself exists
+func seekAfter(c *bolt.Cursor, key []byte, ascending bool) ([]byte, []byte) {
+	k, v := c.Seek(key)
+	switch {
+	case ascending && bytes.Equal(k, key):
+		return c.Next()
+	case ascending:
+		return k, v
+	case k == nil:
+		return c.Last()
+	default:
+		return c.Prev()
+	}
+}
+
+// lastWithPrefix positions the cursor on the last key starting with prefix
+func lastWithPrefix(c *bolt.Cursor, prefix []byte) ([]byte, []byte) {
+	if len(prefix) == 0 {
+		return c.Last()
+	}
+	// Category prefixes end in 0x00, so the next category starts at 0x01
+	upper := append(append([]byte{}, prefix[:len(prefix)-1]...), 1)
+	if k, _ := c.Seek(upper); k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
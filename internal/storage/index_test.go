@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+func TestOpenFileStoreRebuildsIndex(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ProcessedPath:    filepath.Join(dir, "processed"),
+		StorageIndexPath: filepath.Join(dir, "index.db"),
+	}
+
+	// Files written before the index existed
+	plain, err := NewFileStore(cfg.ProcessedPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i, category := range []string{"economy", "sports", "economy"} {
+		item := &models.NewsItem{
+			ID:        string(rune('a' + i)),
+			Category:  category,
+			Source:    "https://feeds.example.com/rss",
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		}
+		if err := plain.Save(ctx, item); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	corrupt := filepath.Join(cfg.ProcessedPath, "1_broken.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt file: %v", err)
+	}
+
+	store, err := OpenFileStore(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to open indexed store: %v", err)
+	}
+	if n, _ := store.index.Len(); n != 3 {
+		t.Errorf("Expected 3 indexed items, the corrupt file skipped, got %d", n)
+	}
+	meta, err := store.index.Get("b")
+	if err != nil || meta.Category != "sports" || meta.Source != "https://feeds.example.com/rss" {
+		t.Errorf("Unexpected metadata for b: %+v, %v", meta, err)
+	}
+
+	economy, err := store.List(ctx, Query{Category: "economy"})
+	if err != nil || len(economy) != 2 || economy[0].ID != "c" || economy[1].ID != "a" {
+		t.Errorf("Expected c and a in the economy category, got %v, %v", economy, err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// The index persists and is not rebuilt when reopened
+	if err := os.RemoveAll(cfg.ProcessedPath); err != nil {
+		t.Fatalf("Failed to remove files: %v", err)
+	}
+	store, err = OpenFileStore(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to reopen indexed store: %v", err)
+	}
+	defer store.Close()
+	if n, _ := store.index.Len(); n != 3 {
+		t.Errorf("Expected the index to keep 3 items, got %d", n)
+	}
+	if n, err := store.Reindex(ctx); err != nil || n != 0 {
+		t.Errorf("Expected an explicit reindex to reflect the files on disk, got %d, %v", n, err)
+	}
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after reindexing, got %v", err)
+	}
+}
+
+func TestOpenIndexDropsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	idx, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	if err := idx.Put(Meta{ID: "a", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	err = idx.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketInfo).Put(keyVersion, []byte("1"))
+	})
+	if err != nil {
+		t.Fatalf("Failed to set version: %v", err)
+	}
+	idx.Close()
+
+	idx, err = OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen index: %v", err)
+	}
+	defer idx.Close()
+	if n, _ := idx.Len(); n != 0 {
+		t.Errorf("Expected an outdated index to be emptied for a rebuild, got %d items", n)
+	}
+}
+
+func TestIndexCountsByStatusAndCategory(t *testing.T) {
+	idx, err := OpenIndex(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	defer idx.Close()
+
+	now := time.Now()
+	for _, meta := range []Meta{
+		{ID: "a", CreatedAt: now, Category: "economy"},
+		{ID: "b", CreatedAt: now, Category: "economy", Status: models.StatusDraft},
+		{ID: "c", CreatedAt: now, Category: "sports", Status: models.StatusPublished},
+		{ID: "d", CreatedAt: now, Status: models.StatusDraft},
+	} {
+		if err := idx.Put(meta); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	// Moving and deleting items keeps the counts in step
+	if err := idx.Put(Meta{ID: "b", CreatedAt: now, Category: "sports", Status: models.StatusPublished}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := idx.Delete("a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		q    Query
+		want int
+	}{
+		{Query{Status: models.StatusPublished}, 2},
+		{Query{Status: models.StatusDraft}, 1},
+		{Query{Category: "sports"}, 2},
+		{Query{Category: "economy"}, 0},
+		{Query{Status: models.StatusPublished, Category: "sports", Limit: 1}, 2},
+		{Query{Status: models.StatusRejected}, 0},
+		{Query{Status: models.StatusDraft, Source: "aa"}, 0},
+	} {
+		if n, err := idx.Count(tc.q); err != nil || n != tc.want {
+			t.Errorf("Expected %d items for %+v, got %d, %v", tc.want, tc.q, n, err)
+		}
+	}
+}
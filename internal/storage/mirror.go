@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// MirrorStore writes to a primary store and copies every change to mirrors.
+// Reads use the primary only. Mirror failures are logged but do not fail the
+// write, so a mirror outage never loses an item the primary accepted.
+type MirrorStore struct {
+	primary Store
+	mirrors map[string]Store
+	names   []string
+}
+
+// NewMirrorStore creates a store on top of primary; mirrors are keyed by name for logging
+func NewMirrorStore(primary Store, mirrors map[string]Store) *MirrorStore {
+	m := &MirrorStore{primary: primary, mirrors: mirrors}
+	for name := range mirrors {
+		m.names = append(m.names, name)
+	}
+	sort.Strings(m.names)
+	return m
+}
+
+func (m *MirrorStore) Save(ctx context.Context, item *models.NewsItem) error {
+	if err := m.primary.Save(ctx, item); err != nil {
+		return err
+	}
+	m.each(item.ID, "save", func(s Store) error {
+		return s.Save(ctx, item)
+	})
+	return nil
+}
+
+func (m *MirrorStore) Get(ctx context.Context, id string) (*models.NewsItem, error) {
+	return m.primary.Get(ctx, id)
+}
+
+func (m *MirrorStore) List(ctx context.Context, q Query) ([]*models.NewsItem, error) {
+	return m.primary.List(ctx, q)
+}
+
+func (m *MirrorStore) Count(ctx context.Context, q Query) (int, error) {
+	return m.primary.Count(ctx, q)
+}
+
+// Update checks version against the primary only; mirrors follow it
+func (m *MirrorStore) Update(ctx context.Context, item *models.NewsItem, version string) error {
+	if err := m.primary.Update(ctx, item, version); err != nil {
+		return err
+	}
+	m.each(item.ID, "update", func(s Store) error {
+		err := s.Update(ctx, item, "")
+		if errors.Is(err, ErrNotFound) {
+			// The mirror missed the original save; copy the item now
+			return s.Save(ctx, item)
+		}
+		return err
+	})
+	return nil
+}
+
+func (m *MirrorStore) Delete(ctx context.Context, id string) error {
+	if err := m.primary.Delete(ctx, id); err != nil {
+		return err
+	}
+	m.each(id, "delete", func(s Store) error {
+		if err := s.Delete(ctx, id); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+func (m *MirrorStore) SaveRevision(ctx context.Context, rev *models.Revision) error {
+	if err := m.primary.SaveRevision(ctx, rev); err != nil {
+		return err
+	}
+	m.each(rev.NewsID, "save revision", func(s Store) error {
+		if err := s.SaveRevision(ctx, rev); err != nil && !errors.Is(err, ErrRevisionExists) {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+func (m *MirrorStore) Revisions(ctx context.Context, id string) ([]*models.Revision, error) {
+	return m.primary.Revisions(ctx, id)
+}
+
+func (m *MirrorStore) SaveSourceItem(ctx context.Context, id string, item *models.FeedItem) error {
+	if err := m.primary.SaveSourceItem(ctx, id, item); err != nil {
+		return err
+	}
+	m.each(id, "save source item", func(s Store) error {
+		return s.SaveSourceItem(ctx, id, item)
+	})
+	return nil
+}
+
+func (m *MirrorStore) SourceItem(ctx context.Context, id string) (*models.FeedItem, error) {
+	return m.primary.SourceItem(ctx, id)
+}
+
+// Close closes the primary and mirrors that hold resources
+func (m *MirrorStore) Close() error {
+	var errs []error
+	for _, s := range append([]Store{m.primary}, m.stores()...) {
+		if c, ok := s.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MirrorStore) stores() []Store {
+	stores := make([]Store, 0, len(m.names))
+	for _, name := range m.names {
+		stores = append(stores, m.mirrors[name])
+	}
+	return stores
+}
+
+// each applies op to every mirror and logs failures
+func (m *MirrorStore) each(id, op string, fn func(Store) error) {
+	for _, name := range m.names {
+		if err := fn(m.mirrors[name]); err != nil {
+			logger.Get().Error().
+				Err(err).
+				Str("id", id).
+				Str("mirror", name).
+				Str("op", op).
+				Msg("Failed to mirror news item")
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bilgisen/goen/internal/config"
+	"github.com/bilgisen/goen/internal/logger"
+)
+
+// Storage backends
+const (
+	BackendFilesystem = "filesystem"
+	BackendR2         = "r2"
+)
+
+// Open creates the configured primary store and wraps it with the configured
+// mirrors. Mirrors without complete configuration are skipped with a warning.
+func Open(ctx context.Context, cfg *config.Config) (Store, error) {
+	primary, err := openBackend(ctx, cfg, cfg.StorageBackend, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s storage: %w", cfg.StorageBackend, err)
+	}
+
+	mirrors := make(map[string]Store)
+	for _, name := range cfg.StorageMirrors {
+		if name == cfg.StorageBackend {
+			continue
+		}
+		if name == BackendR2 && !r2Configured(cfg) {
+			logger.Get().Warn().
+				Str("r2_endpoint", cfg.R2Endpoint).
+				Str("r2_bucket", cfg.R2Bucket).
+				Msg("R2 credentials incomplete or missing, not mirroring to R2")
+			continue
+		}
+		mirror, err := openBackend(ctx, cfg, name, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s mirror: %w", name, err)
+		}
+		mirrors[name] = mirror
+		logger.Get().Info().Str("mirror", name).Msg("Mirroring news items")
+	}
+
+	if len(mirrors) == 0 {
+		return primary, nil
+	}
+	return NewMirrorStore(primary, mirrors), nil
+}
+
+// openBackend opens the named store. Mirrors are never read from, so an R2
+// mirror goes without its index.
+func openBackend(ctx context.Context, cfg *config.Config, name string, primary bool) (Store, error) {
+	switch name {
+	case BackendFilesystem:
+		return OpenFileStore(ctx, cfg)
+	case BackendR2:
+		if primary {
+			return OpenR2Store(ctx, cfg)
+		}
+		return NewS3Store(ctx, r2Config(cfg))
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", name)
+	}
+}
+
+func r2Config(cfg *config.Config) S3Config {
+	return S3Config{
+		Endpoint:  cfg.R2Endpoint,
+		AccessKey: cfg.R2AccessKey,
+		SecretKey: cfg.R2SecretKey,
+		Bucket:    cfg.R2Bucket,
+	}
+}
+
+func r2Configured(cfg *config.Config) bool {
+	return cfg.R2Endpoint != "" && cfg.R2AccessKey != "" && cfg.R2SecretKey != "" && cfg.R2Bucket != ""
+}
+
+// OpenFileStore creates the filesystem store with its metadata index, if
+// configured. An empty index is rebuilt from the files on disk.
+func OpenFileStore(ctx context.Context, cfg *config.Config) (*FileStore, error) {
+	store, err := NewFileStore(cfg.ProcessedPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.StorageIndexPath == "" {
+		return store, nil
+	}
+
+	idx, err := OpenIndex(cfg.StorageIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	store.SetIndex(idx)
+
+	n, err := idx.Len()
+	if err == nil && n == 0 {
+		_, err = store.Reindex(ctx)
+	}
+	if err != nil {
+		idx.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// OpenR2Store connects to the R2 bucket and opens its metadata index, if
+// configured. An empty index is rebuilt from the objects in the bucket.
+func OpenR2Store(ctx context.Context, cfg *config.Config) (*S3Store, error) {
+	store, err := NewS3Store(ctx, r2Config(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.R2IndexPath == "" {
+		return store, nil
+	}
+
+	idx, err := OpenIndex(cfg.R2IndexPath)
+	if err != nil {
+		return nil, err
+	}
+	store.SetIndex(idx)
+
+	n, err := idx.Len()
+	if err == nil && n == 0 {
+		_, err = store.Reindex(ctx)
+	}
+	if err != nil {
+		idx.Close()
+		return nil, err
+	}
+	return store, nil
+}
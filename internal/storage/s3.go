@@ -0,0 +1,468 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/bilgisen/goen/internal/logger"
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// S3API is the subset of the S3 client used by S3Store, so tests can use a fake
+type S3API interface {
+	PutObject(ctx context.Context, in *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, in *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, in *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// S3Config configures an S3-compatible bucket such as Cloudflare R2 or MinIO
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+}
+
+// S3Store keeps news items as JSON objects under processed/<id>.json,
+// their revisions under revisions/<id>/<number>.json and the feed items
+// they were generated from under sources/<id>.json. With an index, lists
+// and counts download only the objects they return; without one they
+// download every object.
+//
+// Conditional updates send the ETag of the object they checked as If-Match,
+// so the bucket rejects them if another writer got there first. The index
+// only sees this process's writes, so instances sharing a bucket must
+// reindex to see each other's items.
+type S3Store struct {
+	client         S3API
+	index          *Index
+	bucket         string
+	prefix         string
+	revisionPrefix string
+	sourcePrefix   string
+}
+
+// NewS3Store connects to an S3-compatible bucket
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.AccessKey == "" || cfg.SecretKey == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 configuration is incomplete")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "auto"
+	}
+
+	awsCfg, err := awsConfig.LoadDefaultConfig(ctx,
+		awsConfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			cfg.AccessKey, cfg.SecretKey, "")),
+		awsConfig.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load S3 config: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(cfg.Endpoint)
+		o.UsePathStyle = true
+	})
+	return NewS3StoreWithClient(client, cfg.Bucket), nil
+}
+
+// NewS3StoreWithClient creates a store on top of an existing client
+func NewS3StoreWithClient(client S3API, bucket string) *S3Store {
+	return &S3Store{client: client, bucket: bucket, prefix: "processed/", revisionPrefix: "revisions/", sourcePrefix: "sources/"}
+}
+
+// SetIndex makes the store maintain and read from idx
+func (s *S3Store) SetIndex(idx *Index) {
+	s.index = idx
+}
+
+// Reindex rebuilds the index from the objects in the bucket and returns the
+// number of indexed items. Corrupt objects are skipped.
+func (s *S3Store) Reindex(ctx context.Context) (int, error) {
+	if s.index == nil {
+		return 0, fmt.Errorf("store has no index")
+	}
+	keys, err := s.keys(ctx, s.prefix)
+	if err != nil {
+		return 0, err
+	}
+	metas := make([]Meta, 0, len(keys))
+	for _, key := range keys {
+		item, err := s.get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if errors.Is(err, ErrCorrupt) {
+			logger.Get().Warn().Err(err).Msg("Skipping corrupt news object")
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		metas = append(metas, MetaOf(item, key))
+	}
+
+	if err := s.index.Reset(metas); err != nil {
+		return 0, fmt.Errorf("failed to rebuild index: %w", err)
+	}
+	logger.Get().Info().Int("items", len(metas)).Msg("Rebuilt news index")
+	return len(metas), nil
+}
+
+// Close closes the index, if any
+func (s *S3Store) Close() error {
+	if s.index == nil {
+		return nil
+	}
+	return s.index.Close()
+}
+
+func (s *S3Store) key(id string) string {
+	return s.prefix + id + ".json"
+}
+
+// Save uploads a news item
+func (s *S3Store) Save(ctx context.Context, item *models.NewsItem) error {
+	if err := s.put(ctx, item, ""); err != nil {
+		return err
+	}
+	return s.indexItem(item)
+}
+
+// Get downloads the news item with the given ID
+func (s *S3Store) Get(ctx context.Context, id string) (*models.NewsItem, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	return s.get(ctx, s.key(id))
+}
+
+// List returns the news items matching the query. With an index only the
+// objects of the page are downloaded.
+func (s *S3Store) List(ctx context.Context, q Query) ([]*models.NewsItem, error) {
+	if s.index != nil {
+		metas, err := s.index.List(q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index: %w", err)
+		}
+		items := make([]*models.NewsItem, 0, len(metas))
+		for _, meta := range metas {
+			item, err := s.get(ctx, meta.Path)
+			if errors.Is(err, ErrNotFound) {
+				logger.Get().Warn().Str("id", meta.ID).Str("key", meta.Path).Msg("Indexed news object is missing, reindex the store")
+				continue
+			}
+			if errors.Is(err, ErrCorrupt) {
+				logger.Get().Warn().Err(err).Msg("Skipping corrupt news object")
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	items, err := s.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.apply(items), nil
+}
+
+// Count returns the number of news items matching the query's filters
+func (s *S3Store) Count(ctx context.Context, q Query) (int, error) {
+	if s.index != nil {
+		n, err := s.index.Count(q)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read index: %w", err)
+		}
+		return n, nil
+	}
+
+	items, err := s.all(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(q.counted().apply(items)), nil
+}
+
+// all downloads every news item under the prefix
+func (s *S3Store) all(ctx context.Context) ([]*models.NewsItem, error) {
+	keys, err := s.keys(ctx, s.prefix)
+	if err != nil {
+		return nil, err
+	}
+	var items []*models.NewsItem
+	for _, key := range keys {
+		item, err := s.get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			// Deleted since it was listed
+			continue
+		}
+		if errors.Is(err, ErrCorrupt) {
+			logger.Get().Warn().Err(err).Msg("Skipping corrupt news object")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Update replaces a stored news item. The object is only overwritten if its
+// ETag still matches the one read, so a concurrent writer in another process
+// makes the update fail with ErrConflict instead of being lost.
+func (s *S3Store) Update(ctx context.Context, item *models.NewsItem, version string) error {
+	if item.ID == "" {
+		return ErrNotFound
+	}
+	data, etag, err := s.getObject(ctx, s.key(item.ID))
+	if err != nil {
+		return err
+	}
+	if version != "" {
+		var stored models.NewsItem
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrCorrupt, s.key(item.ID), err)
+		}
+		if VersionOf(&stored) != version {
+			return fmt.Errorf("%w: %s", ErrConflict, item.ID)
+		}
+	}
+	if err := s.put(ctx, item, etag); err != nil {
+		return err
+	}
+	return s.indexItem(item)
+}
+
+// Delete removes a news item
+func (s *S3Store) Delete(ctx context.Context, id string) error {
+	// S3 deletes succeed for missing keys, so check first
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	keys, err := s.keys(ctx, s.revisionKeyPrefix(id))
+	if err != nil {
+		return err
+	}
+	for _, key := range append(keys, s.sourcePrefix+id+".json", s.key(id)) {
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete object %s: %w", key, err)
+		}
+	}
+	if s.index != nil {
+		if err := s.index.Delete(id); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to remove news item from index: %w", err)
+		}
+	}
+	return nil
+}
+
+// SaveRevision uploads a revision
+func (s *S3Store) SaveRevision(ctx context.Context, rev *models.Revision) error {
+	if rev.NewsID == "" || rev.Number < 1 {
+		return fmt.Errorf("invalid revision %d of %q", rev.Number, rev.NewsID)
+	}
+	key := fmt.Sprintf("%s%d.json", s.revisionKeyPrefix(rev.NewsID), rev.Number)
+	_, _, err := s.getObject(ctx, key)
+	if err == nil {
+		return fmt.Errorf("%w: %s revision %d", ErrRevisionExists, rev.NewsID, rev.Number)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return s.putJSON(ctx, key, rev, "")
+}
+
+// Revisions downloads the revisions of a news item
+func (s *S3Store) Revisions(ctx context.Context, id string) ([]*models.Revision, error) {
+	keys, err := s.keys(ctx, s.revisionKeyPrefix(id))
+	if err != nil {
+		return nil, err
+	}
+	revs := make([]*models.Revision, 0, len(keys))
+	for _, key := range keys {
+		data, _, err := s.getObject(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var rev models.Revision
+		if err := json.Unmarshal(data, &rev); err != nil {
+			logger.Get().Warn().Err(err).Str("key", key).Msg("Skipping corrupt revision object")
+			continue
+		}
+		revs = append(revs, &rev)
+	}
+	sortRevisions(revs)
+	return revs, nil
+}
+
+// SaveSourceItem uploads the feed item of a news item
+func (s *S3Store) SaveSourceItem(ctx context.Context, id string, item *models.FeedItem) error {
+	if id == "" {
+		return fmt.Errorf("invalid news ID %q", id)
+	}
+	return s.putJSON(ctx, s.sourcePrefix+id+".json", item, "")
+}
+
+// SourceItem downloads the feed item of a news item
+func (s *S3Store) SourceItem(ctx context.Context, id string) (*models.FeedItem, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	key := s.sourcePrefix + id + ".json"
+	data, _, err := s.getObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var item models.FeedItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, key, err)
+	}
+	return &item, nil
+}
+
+func (s *S3Store) revisionKeyPrefix(id string) string {
+	return s.revisionPrefix + id + "/"
+}
+
+// keys lists the JSON object keys under prefix
+func (s *S3Store) keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if key := aws.ToString(obj.Key); strings.HasSuffix(key, ".json") {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// indexItem records the metadata of an uploaded news item, if indexed
+func (s *S3Store) indexItem(item *models.NewsItem) error {
+	if s.index == nil {
+		return nil
+	}
+	if err := s.index.Put(MetaOf(item, s.key(item.ID))); err != nil {
+		return fmt.Errorf("failed to index news item: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Store) put(ctx context.Context, item *models.NewsItem, etag string) error {
+	return s.putJSON(ctx, s.key(item.ID), item, etag)
+}
+
+// putJSON uploads v as JSON. A non-empty etag makes the upload conditional
+// on the object still having that ETag, and fail with ErrConflict otherwise.
+func (s *S3Store) putJSON(ctx context.Context, key string, v interface{}, etag string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+
+	in := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	}
+	if etag != "" {
+		in.IfMatch = aws.String(etag)
+	}
+	_, err = s.client.PutObject(ctx, in)
+	if err != nil {
+		if preconditionFailed(err) {
+			return fmt.Errorf("%w: %s", ErrConflict, key)
+		}
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	return nil
+}
+
+// preconditionFailed reports whether the bucket rejected a conditional
+// request. A conditional upload racing another one may also be answered
+// with 409 ConditionalRequestConflict.
+func preconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			return true
+		}
+	}
+	var respErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusPreconditionFailed
+}
+
+// getObject downloads an object and returns it with its ETag, or returns
+// ErrNotFound
+func (s *S3Store) getObject(ctx context.Context, key string) ([]byte, string, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, "", fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, "", fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	return data, aws.ToString(out.ETag), nil
+}
+
+func (s *S3Store) get(ctx context.Context, key string) (*models.NewsItem, error) {
+	data, _, err := s.getObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var item models.NewsItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, key, err)
+	}
+	return &item, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -12,216 +13,625 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bilgisen/goen/internal/logger"
 	"github.com/bilgisen/goen/internal/models"
+	"github.com/bilgisen/goen/internal/utils"
 )
 
-func NewStorage(basePath string) (*Storage, error) {
-	// Create base directory if it doesn't exist
-	if err := os.MkdirAll(basePath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create storage directory: %w", err)
-	}
+// FileStore keeps news items as JSON files in dated directories:
+// processed/YYYY/MM/DD/<unix>_<id>.json. With an index, lookups and pages
+// read only the files they return; without one they walk the tree.
+//
+// Files are written to a temporary file and renamed into place, so a crash
+// never leaves a truncated item behind. Files that cannot be decoded anyway
+// are moved to processed/quarantine and skipped.
+type FileStore struct {
+	root  string
+	index *Index
+	mu    sync.RWMutex
+}
 
-	// Create processed directory if it doesn't exist
-	processedPath := filepath.Join(basePath, "processed")
-	if err := os.MkdirAll(processedPath, 0755); err != nil {
+func NewFileStore(basePath string) (*FileStore, error) {
+	// Items live in the processed directory, which basePath may already be
+	root := basePath
+	if !strings.HasSuffix(strings.TrimRight(basePath, "/"), "processed") {
+		root = filepath.Join(basePath, "processed")
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create processed directory: %w", err)
 	}
 
-	return &Storage{
-		basePath: basePath,
-	}, nil
+	s := &FileStore{root: root}
+	s.removeTempFiles()
+	return s, nil
 }
 
-type Storage struct {
-	basePath string
-	mu      sync.RWMutex
+// removeTempFiles deletes the temporary files of writes interrupted by a crash
+func (s *FileStore) removeTempFiles() {
+	filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasPrefix(d.Name(), ".") && strings.Contains(d.Name(), ".tmp-") {
+			if err := os.Remove(path); err == nil {
+				logger.Get().Info().Str("path", path).Msg("Removed temporary file of an interrupted write")
+			}
+		}
+		return nil
+	})
 }
 
-// SaveNews saves a news item to disk
-func (s *Storage) SaveNews(ctx context.Context, item *models.NewsItem) error {
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-		s.mu.Lock()
-		defer s.mu.Unlock()
+// SetIndex makes the store maintain and read from idx
+func (s *FileStore) SetIndex(idx *Index) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = idx
+}
 
-		// Create dated directory (YYYY/MM/DD)
-		datePath := s.basePath
-		if !strings.HasSuffix(strings.TrimRight(s.basePath, "/"), "processed") {
-			datePath = filepath.Join(s.basePath, "processed")
-		}
-		datePath = filepath.Join(datePath, time.Now().Format("2006/01/02"))
-		if err := os.MkdirAll(datePath, 0755); err != nil {
-			return fmt.Errorf("failed to create date directory: %w", err)
-		}
+// Directories of the store root that hold no current news files
+const (
+	quarantineDir = "quarantine" // corrupt news files
+	revisionsDir  = "revisions"  // revisions/<id>/<number>.json
+	sourcesDir    = "sources"    // sources/<id>.json, the feed item of each news item
+)
 
-		// Create filename with timestamp and ID
-		filename := fmt.Sprintf("%d_%s.json", time.Now().Unix(), item.ID)
-		filePath := filepath.Join(datePath, filename)
+// Reindex rebuilds the index from the files on disk and returns the number
+// of indexed items. Corrupt files are quarantined, other unreadable files
+// skipped.
+func (s *FileStore) Reindex(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index == nil {
+		return 0, fmt.Errorf("store has no index")
+	}
 
-		// Marshal to JSON
-		data, err := json.MarshalIndent(item, "", "  ")
+	files, err := s.files()
+	if err != nil {
+		return 0, err
+	}
+	metas := make([]Meta, 0, len(files))
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		item, err := readItem(file)
+		if errors.Is(err, ErrCorrupt) {
+			s.quarantine(file, err)
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("failed to marshal news item: %w", err)
+			logger.Get().Warn().Err(err).Str("path", file).Msg("Skipping unreadable news file")
+			continue
 		}
+		metas = append(metas, MetaOf(item, file))
+	}
+
+	if err := s.index.Reset(metas); err != nil {
+		return 0, fmt.Errorf("failed to rebuild index: %w", err)
+	}
+	logger.Get().Info().Int("items", len(metas)).Msg("Rebuilt news index")
+	return len(metas), nil
+}
 
-		// Write to file
-		if err := os.WriteFile(filePath, data, 0644); err != nil {
-			return fmt.Errorf("failed to write news file: %w", err)
+// Close closes the index, if any
+func (s *FileStore) Close() error {
+	if s.index == nil {
+		return nil
+	}
+	return s.index.Close()
+}
+
+// Save writes a news item to the directory of the current day
+func (s *FileStore) Save(ctx context.Context, item *models.NewsItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Updates go through Update, so a stored ID is never written twice
+	if _, err := s.find(item.ID); err == nil {
+		return fmt.Errorf("%w: %s", ErrExists, item.ID)
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	// Create dated directory (YYYY/MM/DD)
+	now := time.Now()
+	datePath := filepath.Join(s.root, now.Format("2006/01/02"))
+	if err := os.MkdirAll(datePath, 0755); err != nil {
+		return fmt.Errorf("failed to create date directory: %w", err)
+	}
+
+	// Create filename with timestamp and ID
+	filePath := filepath.Join(datePath, fmt.Sprintf("%d_%s.json", now.Unix(), item.ID))
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("news file %s already exists", filePath)
+	}
+	if err := writeItem(filePath, item); err != nil {
+		return err
+	}
+	if s.index != nil {
+		if err := s.index.Put(MetaOf(item, filePath)); err != nil {
+			// An unindexed file would be invisible, so undo the write
+			os.Remove(filePath)
+			return fmt.Errorf("failed to index news item: %w", err)
 		}
+	}
 
-		// Update the item's file path
-		item.FilePath = filePath
+	// Update the item's file path
+	item.FilePath = filePath
+	return nil
+}
 
-		return nil
+// Get reads the news item with the given ID
+func (s *FileStore) Get(ctx context.Context, id string) (*models.NewsItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var corrupt []string
+	defer func() { s.quarantineAll(corrupt) }()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	path, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.readTolerant(path, &corrupt)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrCorrupt) {
+		// Removed behind the index's back, or just quarantined
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
+	return item, err
 }
 
-// GetNewsByID retrieves a news item by its ID
-func (s *Storage) GetNewsByID(ctx context.Context, id string) (*models.NewsItem, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		s.mu.RLock()
-		defer s.mu.RUnlock()
+// List reads the news items matching the query in its order. Without
+// filters only the files of the requested page are read.
+func (s *FileStore) List(ctx context.Context, q Query) ([]*models.NewsItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var corrupt []string
+	defer func() { s.quarantineAll(corrupt) }()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-		var foundItem *models.NewsItem
-		searchPath := s.basePath
-		if !strings.HasSuffix(strings.TrimRight(s.basePath, "/"), "processed") {
-			searchPath = filepath.Join(s.basePath, "processed")
+	if s.index != nil {
+		metas, err := s.index.List(q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index: %w", err)
 		}
-		err := filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
+		items := make([]*models.NewsItem, 0, len(metas))
+		for _, meta := range metas {
+			item, err := s.readTolerant(meta.Path, &corrupt)
+			if errors.Is(err, fs.ErrNotExist) {
+				logger.Get().Warn().Str("id", meta.ID).Str("path", meta.Path).Msg("Indexed news file is missing, reindex the store")
+				continue
 			}
-
-			if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
-				return nil
+			if errors.Is(err, ErrCorrupt) {
+				continue
 			}
-
-			// Check if this is the file we're looking for
-			if strings.Contains(d.Name(), id) {
-				data, err := os.ReadFile(path)
-				if err != nil {
-					return fmt.Errorf("failed to read file %s: %w", path, err)
-				}
-
-				var item models.NewsItem
-				if err := json.Unmarshal(data, &item); err != nil {
-					return fmt.Errorf("failed to unmarshal news item: %w", err)
-				}
-
-				foundItem = &item
-				foundItem.FilePath = path
-				return filepath.SkipDir
+			if err != nil {
+				return nil, err
 			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
 
-			return nil
-		})
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
 
+	if q.filtered() {
+		items, err := s.readItems(files, &corrupt)
 		if err != nil {
-			return nil, fmt.Errorf("error walking the path: %w", err)
+			return nil, err
 		}
+		return q.apply(items), nil
+	}
 
-		if foundItem == nil {
-			return nil, fmt.Errorf("news item with ID %s not found", id)
+	// Dated directories and timestamped names sort by creation time. Corrupt
+	// files are skipped, so read on until the page is full.
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	start, end := pageBounds(q, len(files))
+	items := make([]*models.NewsItem, 0, end-start)
+	for _, file := range files[start:] {
+		if len(items) == end-start {
+			break
 		}
-
-		return foundItem, nil
+		item, err := s.readTolerant(file, &corrupt)
+		if errors.Is(err, ErrCorrupt) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
 	}
+	return items, nil
 }
 
-// ListNews retrieves a paginated list of news items
-func (s *Storage) ListNews(ctx context.Context, page, pageSize int) ([]*models.NewsItem, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		s.mu.RLock()
-		defer s.mu.RUnlock()
+// Count returns the number of news items matching the query's filters
+func (s *FileStore) Count(ctx context.Context, q Query) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var corrupt []string
+	defer func() { s.quarantineAll(corrupt) }()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-		var newsItems []*models.NewsItem
-		processedPath := s.basePath
-		// If basePath already ends with "processed" or "processed/", use it directly
-		// Otherwise, append "processed" to it
-		if !strings.HasSuffix(strings.TrimRight(s.basePath, "/"), "processed") {
-			processedPath = filepath.Join(s.basePath, "processed")
+	if s.index != nil {
+		n, err := s.index.Count(q)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read index: %w", err)
 		}
+		return n, nil
+	}
 
-		// Get all JSON files
-		var files []string
-		err := filepath.Walk(processedPath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
-				files = append(files, path)
-			}
-			return nil
-		})
+	files, err := s.files()
+	if err != nil {
+		return 0, err
+	}
+	q = q.counted()
+	if !q.filtered() {
+		return len(files), nil
+	}
+	items, err := s.readItems(files, &corrupt)
+	if err != nil {
+		return 0, err
+	}
+	return len(q.apply(items)), nil
+}
+
+// Update overwrites the file of a stored news item
+func (s *FileStore) Update(ctx context.Context, item *models.NewsItem, version string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	path, err := s.find(item.ID)
+	if err != nil {
+		return err
+	}
+	if version != "" {
+		stored, err := readItem(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotFound, item.ID)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("error walking the path: %w", err)
+			return err
+		}
+		if VersionOf(stored) != version {
+			return fmt.Errorf("%w: %s", ErrConflict, item.ID)
+		}
+	}
+	item.FilePath = path
+	if err := writeItem(path, item); err != nil {
+		return err
+	}
+	if s.index != nil {
+		if err := s.index.Put(MetaOf(item, path)); err != nil {
+			return fmt.Errorf("failed to index news item: %w", err)
 		}
+	}
+	return nil
+}
 
-		// Sort by modification time (newest first)
-		sort.Slice(files, func(i, j int) bool {
-			info1, _ := os.Stat(files[i])
-			info2, _ := os.Stat(files[j])
-			return info1.ModTime().After(info2.ModTime())
-		})
+// Delete removes the file of a news item
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		// Apply pagination
-		start := (page - 1) * pageSize
-		if start >= len(files) {
-			return []*models.NewsItem{}, nil
+	path, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete news file: %w", err)
+	}
+	if s.index != nil {
+		if err := s.index.Delete(id); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to remove news item from index: %w", err)
 		}
+	}
+	if err := os.RemoveAll(s.revisionDir(id)); err != nil {
+		return fmt.Errorf("failed to delete revisions: %w", err)
+	}
+	if err := os.Remove(s.sourcePath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete source item: %w", err)
+	}
+	return nil
+}
 
-		end := start + pageSize
-		if end > len(files) {
-			end = len(files)
-		}
+// SaveRevision writes a revision to revisions/<id>/<number>.json
+func (s *FileStore) SaveRevision(ctx context.Context, rev *models.Revision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !validID(rev.NewsID) || rev.Number < 1 {
+		return fmt.Errorf("invalid revision %d of %q", rev.Number, rev.NewsID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		// Read and unmarshal the files
-		for _, file := range files[start:end] {
-			data, err := os.ReadFile(file)
-			if err != nil {
-				return nil, fmt.Errorf("error reading file %s: %w", file, err)
-			}
+	dir := s.revisionDir(rev.NewsID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create revision directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d.json", rev.Number))
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s revision %d", ErrRevisionExists, rev.NewsID, rev.Number)
+	}
+	return writeJSON(path, rev)
+}
 
-			var item models.NewsItem
-			if err := json.Unmarshal(data, &item); err != nil {
-				return nil, fmt.Errorf("error unmarshaling news item: %w", err)
-			}
+// Revisions reads the revisions of a news item. Unreadable revision files
+// are skipped with a warning.
+func (s *FileStore) Revisions(ctx context.Context, id string) ([]*models.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !validID(id) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-			item.FilePath = file
-			newsItems = append(newsItems, &item)
+	entries, err := os.ReadDir(s.revisionDir(id))
+	if os.IsNotExist(err) {
+		return []*models.Revision{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+	revs := make([]*models.Revision, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(s.revisionDir(id), e.Name())
+		var rev models.Revision
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(data, &rev)
 		}
+		if err != nil {
+			logger.Get().Warn().Err(err).Str("path", path).Msg("Skipping unreadable revision file")
+			continue
+		}
+		revs = append(revs, &rev)
+	}
+	sortRevisions(revs)
+	return revs, nil
+}
+
+func (s *FileStore) revisionDir(id string) string {
+	return filepath.Join(s.root, revisionsDir, id)
+}
+
+// SaveSourceItem writes the feed item of a news item to sources/<id>.json
+func (s *FileStore) SaveSourceItem(ctx context.Context, id string, item *models.FeedItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !validID(id) {
+		return fmt.Errorf("invalid news ID %q", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		return newsItems, nil
+	if err := os.MkdirAll(filepath.Join(s.root, sourcesDir), 0755); err != nil {
+		return fmt.Errorf("failed to create source directory: %w", err)
 	}
+	return writeJSON(s.sourcePath(id), item)
 }
 
-// DeleteNews deletes a news item by its ID
-func (s *Storage) DeleteNews(ctx context.Context, id string) error {
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-		s.mu.Lock()
-		defer s.mu.Unlock()
+// SourceItem reads the feed item of a news item
+func (s *FileStore) SourceItem(ctx context.Context, id string) (*models.FeedItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !validID(id) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-		item, err := s.GetNewsByID(ctx, id)
+	data, err := os.ReadFile(s.sourcePath(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: source item of %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source item: %w", err)
+	}
+	var item models.FeedItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("%w: source item of %s: %v", ErrCorrupt, id, err)
+	}
+	return &item, nil
+}
+
+func (s *FileStore) sourcePath(id string) string {
+	return filepath.Join(s.root, sourcesDir, id+".json")
+}
+
+// validID reports whether id is safe to use as a path element
+func validID(id string) bool {
+	return id != "" && !strings.HasPrefix(id, ".") && !strings.ContainsAny(id, `/\`)
+}
+
+// skipDir reports whether a directory of the root holds no current news files
+func (s *FileStore) skipDir(path string) bool {
+	switch path {
+	case filepath.Join(s.root, quarantineDir), filepath.Join(s.root, revisionsDir), filepath.Join(s.root, sourcesDir):
+		return true
+	}
+	return false
+}
+
+// find returns the path of the file holding the given ID
+func (s *FileStore) find(id string) (string, error) {
+	if id == "" {
+		return "", ErrNotFound
+	}
+	if s.index != nil {
+		meta, err := s.index.Get(id)
 		if err != nil {
-			return err
+			return "", err
 		}
+		return meta.Path, nil
+	}
 
-		if err := os.Remove(item.FilePath); err != nil {
-			return fmt.Errorf("failed to delete news file: %w", err)
+	suffix := "_" + id + ".json"
+
+	var found string
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && s.skipDir(path) {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), suffix) {
+			found = path
+			return fs.SkipAll
 		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error walking the path: %w", err)
+	}
+	if found == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return found, nil
+}
 
+// files returns the paths of all stored news files, leaving out the
+// quarantine, revisions and temporary files of unfinished writes
+func (s *FileStore) files() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && s.skipDir(path) {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") && !strings.HasPrefix(d.Name(), ".") {
+			files = append(files, path)
+		}
 		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking the path: %w", err)
+	}
+	return files, nil
+}
+
+// readItems reads the news items stored in files, skipping corrupt ones
+func (s *FileStore) readItems(files []string, corrupt *[]string) ([]*models.NewsItem, error) {
+	items := make([]*models.NewsItem, 0, len(files))
+	for _, file := range files {
+		item, err := s.readTolerant(file, corrupt)
+		if errors.Is(err, ErrCorrupt) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// readTolerant reads a news file and adds it to corrupt if it cannot be
+// decoded. The returned error still wraps ErrCorrupt so callers can skip the
+// item.
+func (s *FileStore) readTolerant(path string, corrupt *[]string) (*models.NewsItem, error) {
+	item, err := readItem(path)
+	if errors.Is(err, ErrCorrupt) {
+		*corrupt = append(*corrupt, path)
+	}
+	return item, err
+}
+
+// quarantineAll quarantines the corrupt files found by a reader. Readers
+// hold only the read lock, so they call it once they have released it;
+// files fixed or moved in the meantime are left alone.
+func (s *FileStore) quarantineAll(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, path := range paths {
+		if _, err := readItem(path); errors.Is(err, ErrCorrupt) {
+			s.quarantine(path, err)
+		}
+	}
+}
+
+// quarantine moves a corrupt file out of the store and drops it from the
+// index. It must be called with the write lock held.
+func (s *FileStore) quarantine(path string, cause error) {
+	log := logger.Get().Warn().Err(cause).Str("path", path)
+	dir := filepath.Join(s.root, quarantineDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.AnErr("quarantine_error", err).Msg("Failed to quarantine corrupt news file")
+		return
+	}
+	target := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, target); err != nil && !os.IsNotExist(err) {
+		log.AnErr("quarantine_error", err).Msg("Failed to quarantine corrupt news file")
+		return
+	}
+	if s.index != nil {
+		if id, ok := idFromName(filepath.Base(path)); ok {
+			if err := s.index.Delete(id); err != nil && !errors.Is(err, ErrNotFound) {
+				log.AnErr("index_error", err).Msg("Failed to drop quarantined news file from index")
+			}
+		}
+	}
+	log.Str("quarantined_to", target).Msg("Quarantined corrupt news file")
+}
+
+// idFromName returns the item ID of a <unix>_<id>.json file name
+func idFromName(name string) (string, bool) {
+	_, id, ok := strings.Cut(strings.TrimSuffix(name, ".json"), "_")
+	return id, ok && id != ""
+}
+
+func readItem(path string) (*models.NewsItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", path, err)
+	}
+
+	var item models.NewsItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, path, err)
+	}
+	item.FilePath = path
+	return &item, nil
+}
+
+func writeItem(path string, item *models.NewsItem) error {
+	return writeJSON(path, item)
+}
+
+// writeJSON marshals v and writes it atomically, so readers see either the
+// old or the new file but never a partial one
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
 	}
+	return utils.WriteFileAtomic(path, data, 0644)
 }
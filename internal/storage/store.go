@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// ErrNotFound is returned when no news item has the requested ID
+var ErrNotFound = errors.New("news item not found")
+
+// ErrExists is returned when saving a news item whose ID is already stored
+var ErrExists = errors.New("news item already exists")
+
+// ErrRevisionExists is returned when a revision number is already taken
+var ErrRevisionExists = errors.New("revision already exists")
+
+// ErrCorrupt is returned for stored news items that cannot be decoded
+var ErrCorrupt = errors.New("corrupt news item")
+
+// ErrConflict is returned by conditional updates of items that were changed
+// since they were read
+var ErrConflict = errors.New("news item was changed concurrently")
+
+// VersionOf identifies the stored version of a news item for conditional
+// updates. Every write sets UpdatedAt, and status changes keep the
+// revision, so all three are compared.
+func VersionOf(item *models.NewsItem) string {
+	return fmt.Sprintf("%d/%s/%s", item.Revision, item.UpdatedAt.UTC().Format(time.RFC3339Nano), item.CurrentStatus())
+}
+
+// Store persists generated news items
+type Store interface {
+	// Save stores a new news item
+	Save(ctx context.Context, item *models.NewsItem) error
+	// Get returns the news item with the given ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*models.NewsItem, error)
+	// List returns the news items matching the query, newest first
+	List(ctx context.Context, q Query) ([]*models.NewsItem, error)
+	// Count returns the number of items matching the query's filters,
+	// ignoring its page and cursor
+	Count(ctx context.Context, q Query) (int, error)
+	// Update replaces a stored news item with the same ID, or returns
+	// ErrNotFound. Unless version is empty, the stored item must still be
+	// that version (see VersionOf); otherwise Update returns ErrConflict.
+	Update(ctx context.Context, item *models.NewsItem, version string) error
+	// Delete removes the news item with the given ID and its revisions, or
+	// returns ErrNotFound
+	Delete(ctx context.Context, id string) error
+
+	// SaveRevision stores a revision of a news item. Revisions are never
+	// overwritten.
+	SaveRevision(ctx context.Context, rev *models.Revision) error
+	// Revisions returns the stored revisions of a news item, oldest first
+	Revisions(ctx context.Context, id string) ([]*models.Revision, error)
+
+	// SaveSourceItem stores the feed item a news item was generated from,
+	// replacing an earlier one
+	SaveSourceItem(ctx context.Context, id string, item *models.FeedItem) error
+	// SourceItem returns the feed item a news item was generated from, or
+	// ErrNotFound for items stored before source items were kept
+	SourceItem(ctx context.Context, id string) (*models.FeedItem, error)
+}
+
+// Sort orders of news item lists
+const (
+	SortCreated   = "created_at"
+	SortPublished = "published_at"
+)
+
+// Query selects a page of news items. A zero Limit returns every match.
+// Time ranges include From and exclude To; zero bounds are open.
+type Query struct {
+	Offset int
+	Limit  int
+	// After, if set, starts the page after the cursor's position in the
+	// query's order. Its own order fields are ignored.
+	After *Cursor
+	// Category restricts the results to one taxonomy slug
+	Category string
+	// Tag restricts the results to items with the tag, ignoring case
+	Tag string
+	// Source restricts the results to items of one feed source
+	Source string
+	// Language restricts the results to items in one language
+	Language string
+	// Status restricts the results to one editorial state
+	Status string
+
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	PublishedFrom time.Time
+	PublishedTo   time.Time
+
+	// HasImage, if set, restricts the results to items with or without an image
+	HasImage *bool
+
+	// Sort is SortCreated (the default) or SortPublished; items without a
+	// publication date sort by their creation date
+	Sort string
+	// Ascending lists the oldest items first
+	Ascending bool
+}
+
+// filtered reports whether the query selects a subset of the items or
+// orders them other than newest created first
+func (q Query) filtered() bool {
+	return q.Category != "" || q.Tag != "" || q.Source != "" || q.Language != "" || q.Status != "" ||
+		!q.CreatedFrom.IsZero() || !q.CreatedTo.IsZero() ||
+		!q.PublishedFrom.IsZero() || !q.PublishedTo.IsZero() ||
+		q.HasImage != nil || q.Sort == SortPublished || q.Ascending || q.After != nil
+}
+
+// sort returns the sort field of the query
+func (q Query) sort() string {
+	if q.Sort == SortPublished {
+		return SortPublished
+	}
+	return SortCreated
+}
+
+// counted returns the query without its page and cursor
+func (q Query) counted() Query {
+	q.Offset, q.Limit, q.After = 0, 0, nil
+	return q
+}
+
+// beyond reports whether m comes after the query's cursor
+func (q Query) beyond(m Meta) bool {
+	if q.After == nil {
+		return true
+	}
+	return q.less(Meta{ID: q.After.ID, CreatedAt: q.After.Time, PublishedAt: q.After.Time}, m)
+}
+
+// Match reports whether the item satisfies the filters of the query
+func (q Query) Match(item *models.NewsItem) bool {
+	return q.MatchMeta(MetaOf(item, ""))
+}
+
+// MatchMeta reports whether indexed metadata satisfies the filters of the query
+func (q Query) MatchMeta(m Meta) bool {
+	if q.Category != "" && m.Category != q.Category {
+		return false
+	}
+	if q.Source != "" && m.Source != q.Source {
+		return false
+	}
+	if q.Language != "" && !strings.EqualFold(m.language(), q.Language) {
+		return false
+	}
+	if q.Status != "" && m.status() != q.Status {
+		return false
+	}
+	if q.HasImage != nil && m.HasImage != *q.HasImage {
+		return false
+	}
+	if !inRange(m.CreatedAt, q.CreatedFrom, q.CreatedTo) || !inRange(m.published(), q.PublishedFrom, q.PublishedTo) {
+		return false
+	}
+	if q.Tag == "" {
+		return true
+	}
+	for _, tag := range m.Tags {
+		if strings.EqualFold(tag, q.Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange reports whether t lies in [from, to), ignoring zero bounds
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// sortKey returns the time the query orders m by
+func (q Query) sortKey(m Meta) time.Time {
+	if q.Sort == SortPublished {
+		return m.published()
+	}
+	return m.CreatedAt
+}
+
+// less reports whether a is listed before b, breaking ties by ID
+func (q Query) less(a, b Meta) bool {
+	ta, tb := q.sortKey(a), q.sortKey(b)
+	switch {
+	case !ta.Equal(tb) && q.Ascending:
+		return ta.Before(tb)
+	case !ta.Equal(tb):
+		return ta.After(tb)
+	case q.Ascending:
+		return a.ID < b.ID
+	default:
+		return a.ID > b.ID
+	}
+}
+
+// page applies the offset and limit of the query to the items
+func (q Query) page(items []*models.NewsItem) []*models.NewsItem {
+	start, end := pageBounds(q, len(items))
+	return items[start:end]
+}
+
+// pageBounds returns the slice bounds of the query's page among n items
+func pageBounds(q Query, n int) (int, int) {
+	start := q.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+	return start, end
+}
+
+// apply filters, sorts and pages a full set of items
+func (q Query) apply(items []*models.NewsItem) []*models.NewsItem {
+	matched := make([]*models.NewsItem, 0, len(items))
+	for _, item := range items {
+		if meta := MetaOf(item, ""); q.MatchMeta(meta) && q.beyond(meta) {
+			matched = append(matched, item)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return q.less(MetaOf(matched[i], ""), MetaOf(matched[j], ""))
+	})
+	return q.page(matched)
+}
+
+// sortRevisions orders revisions by number
+func sortRevisions(revs []*models.Revision) {
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Number < revs[j].Number })
+}
@@ -0,0 +1,657 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/bilgisen/goen/internal/models"
+)
+
+// fakeS3 is an in-memory stand-in for an S3 bucket. It counts downloads
+// and honours If-Match on uploads; beforePut, if set, runs before each
+// upload is checked.
+type fakeS3 struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	fail      bool
+	gets      int
+	beforePut func(key string)
+}
+
+func etagOf(data []byte) string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%x", md5.Sum(data)))
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[string][]byte)}
+}
+
+var errFakeS3 = errors.New("fake S3 is down")
+
+func (f *fakeS3) PutObject(ctx context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.beforePut != nil {
+		f.beforePut(aws.ToString(in.Key))
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, errFakeS3
+	}
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := aws.ToString(in.Key)
+	if in.IfMatch != nil {
+		stored, ok := f.objects[key]
+		if !ok || etagOf(stored) != aws.ToString(in.IfMatch) {
+			return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+		}
+	}
+	f.objects[key] = data
+	return &s3.PutObjectOutput{ETag: aws.String(etagOf(data))}, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, errFakeS3
+	}
+	data, ok := f.objects[aws.ToString(in.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	f.gets++
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data)), ETag: aws.String(etagOf(data))}, nil
+}
+
+func (f *fakeS3) DeleteObject(ctx context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, errFakeS3
+	}
+	delete(f.objects, aws.ToString(in.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, errFakeS3
+	}
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, aws.ToString(in.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	out := &s3.ListObjectsV2Output{}
+	for _, key := range keys {
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(key)})
+	}
+	return out, nil
+}
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	items := []*models.NewsItem{
+		{ID: "1001", SeoTitle: "Rates held", Category: "economy", CreatedAt: base},
+		{ID: "1002", SeoTitle: "Derby result", Category: "sports", CreatedAt: base.Add(time.Minute)},
+		{ID: "1003", SeoTitle: "Lira slides", Category: "economy", CreatedAt: base.Add(2 * time.Minute)},
+	}
+	for _, item := range items {
+		if err := store.Save(ctx, item); err != nil {
+			t.Fatalf("Save(%s) failed: %v", item.ID, err)
+		}
+	}
+
+	got, err := store.Get(ctx, "1002")
+	if err != nil || got.SeoTitle != "Derby result" {
+		t.Fatalf("Expected to get item 1002, got %+v, %v", got, err)
+	}
+	if _, err := store.Get(ctx, "100"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a partial ID, got %v", err)
+	}
+
+	all, err := store.List(ctx, Query{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("Expected 3 items, got %d, %v", len(all), err)
+	}
+	if all[0].ID != "1003" || all[2].ID != "1001" {
+		t.Errorf("Expected newest first, got %s, %s, %s", all[0].ID, all[1].ID, all[2].ID)
+	}
+	page, err := store.List(ctx, Query{Offset: 1, Limit: 1})
+	if err != nil || len(page) != 1 || page[0].ID != "1002" {
+		t.Errorf("Expected the second item on page 2, got %v, %v", page, err)
+	}
+	economy, err := store.List(ctx, Query{Category: "economy"})
+	if err != nil || len(economy) != 2 || economy[0].ID != "1003" {
+		t.Errorf("Expected 2 economy items, got %v, %v", economy, err)
+	}
+
+	update := *got
+	update.SeoTitle = "Derby ends in a draw"
+	update.UpdatedAt = time.Now()
+	if err := store.Update(ctx, &update, VersionOf(got)); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got, _ := store.Get(ctx, "1002"); got == nil || got.SeoTitle != "Derby ends in a draw" {
+		t.Errorf("Expected the updated title, got %+v", got)
+	}
+	stale := *got
+	stale.SeoTitle = "Stale title"
+	if err := store.Update(ctx, &stale, VersionOf(got)); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict when updating from a stale version, got %v", err)
+	}
+	if got, _ := store.Get(ctx, "1002"); got == nil || got.SeoTitle != "Derby ends in a draw" {
+		t.Errorf("Expected the stale update to be dropped, got %+v", got)
+	}
+	if err := store.Update(ctx, &models.NewsItem{ID: "9999"}, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when updating a missing item, got %v", err)
+	}
+
+	for _, n := range []int{2, 1, 10} {
+		rev := models.NewRevision(&models.NewsItem{ID: "1001", Revision: n, SeoTitle: fmt.Sprint("Title ", n)}, "editor", "")
+		if err := store.SaveRevision(ctx, rev); err != nil {
+			t.Fatalf("SaveRevision(%d) failed: %v", n, err)
+		}
+	}
+	dup := models.NewRevision(&models.NewsItem{ID: "1001", Revision: 2}, "editor", "")
+	if err := store.SaveRevision(ctx, dup); !errors.Is(err, ErrRevisionExists) {
+		t.Errorf("Expected ErrRevisionExists for a taken number, got %v", err)
+	}
+	revs, err := store.Revisions(ctx, "1001")
+	if err != nil || len(revs) != 3 || revs[0].Number != 1 || revs[2].Number != 10 || revs[1].Content.SeoTitle != "Title 2" {
+		t.Errorf("Expected revisions 1, 2 and 10 in order, got %+v, %v", revs, err)
+	}
+	if revs, err := store.Revisions(ctx, "1002"); err != nil || len(revs) != 0 {
+		t.Errorf("Expected no revisions for 1002, got %v, %v", revs, err)
+	}
+
+	if _, err := store.SourceItem(ctx, "1001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound before a source item is saved, got %v", err)
+	}
+	for _, title := range []string{"Faiz sabit", "Faizler sabit kaldı"} {
+		if err := store.SaveSourceItem(ctx, "1001", &models.FeedItem{Guid: "g-1001", TitleTR: title}); err != nil {
+			t.Fatalf("SaveSourceItem failed: %v", err)
+		}
+	}
+	if src, err := store.SourceItem(ctx, "1001"); err != nil || src.TitleTR != "Faizler sabit kaldı" {
+		t.Errorf("Expected the latest source item, got %+v, %v", src, err)
+	}
+	if all, _ := store.List(ctx, Query{}); len(all) != 3 {
+		t.Errorf("Expected revisions and source items to stay out of listings, got %d items", len(all))
+	}
+
+	if err := store.Delete(ctx, "1001"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if revs, err := store.Revisions(ctx, "1001"); err != nil || len(revs) != 0 {
+		t.Errorf("Expected delete to remove the revisions, got %v, %v", revs, err)
+	}
+	if _, err := store.SourceItem(ctx, "1001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected delete to remove the source item, got %v", err)
+	}
+	if _, err := store.Get(ctx, "1001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, "1001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when deleting twice, got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestIndexedFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	idx, err := OpenIndex(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	defer idx.Close()
+	store.SetIndex(idx)
+	testStore(t, store)
+}
+
+func TestS3Store(t *testing.T) {
+	testStore(t, NewS3StoreWithClient(newFakeS3(), "news"))
+}
+
+// newIndexedS3Store returns an S3 store on bucket with an index
+func newIndexedS3Store(t *testing.T, bucket *fakeS3) *S3Store {
+	t.Helper()
+	store := NewS3StoreWithClient(bucket, "news")
+	idx, err := OpenIndex(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatalf("Failed to open index: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	store.SetIndex(idx)
+	return store
+}
+
+func TestIndexedS3Store(t *testing.T) {
+	testStore(t, newIndexedS3Store(t, newFakeS3()))
+}
+
+func TestIndexedS3StoreReadsOnlyThePage(t *testing.T) {
+	ctx := context.Background()
+	bucket := newFakeS3()
+	store := newIndexedS3Store(t, bucket)
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		item := &models.NewsItem{ID: fmt.Sprint(3000 + i), Category: "economy", CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := store.Save(ctx, item); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	bucket.gets = 0
+	page, err := store.List(ctx, Query{Category: "economy", Limit: 2})
+	if err != nil || len(page) != 2 || page[0].ID != "3009" {
+		t.Fatalf("Expected the newest 2 items, got %v, %v", page, err)
+	}
+	if n, err := store.Count(ctx, Query{Category: "economy"}); err != nil || n != 10 {
+		t.Errorf("Expected a count of 10, got %d, %v", n, err)
+	}
+	if bucket.gets != 2 {
+		t.Errorf("Expected only the 2 listed objects to be downloaded, got %d downloads", bucket.gets)
+	}
+
+	// A fresh index is rebuilt from the bucket
+	rebuilt := newIndexedS3Store(t, bucket)
+	if n, err := rebuilt.Reindex(ctx); err != nil || n != 10 {
+		t.Fatalf("Expected 10 reindexed items, got %d, %v", n, err)
+	}
+	if n, err := rebuilt.Count(ctx, Query{}); err != nil || n != 10 {
+		t.Errorf("Expected a count of 10 after reindexing, got %d, %v", n, err)
+	}
+}
+
+func TestS3StoreUpdateChecksETag(t *testing.T) {
+	ctx := context.Background()
+	bucket := newFakeS3()
+	store := NewS3StoreWithClient(bucket, "news")
+	other := NewS3StoreWithClient(bucket, "news")
+	if err := store.Save(ctx, &models.NewsItem{ID: "1001", SeoTitle: "Original", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	current, err := store.Get(ctx, "1001")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	// Another process writes between the version check and the upload
+	bucket.beforePut = func(string) {
+		bucket.beforePut = nil
+		concurrent := *current
+		concurrent.SeoTitle = "Concurrent"
+		if err := other.Update(ctx, &concurrent, ""); err != nil {
+			t.Errorf("Concurrent update failed: %v", err)
+		}
+	}
+	update := *current
+	update.SeoTitle = "Mine"
+	if err := store.Update(ctx, &update, VersionOf(current)); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ErrConflict when the object changed, got %v", err)
+	}
+	if got, _ := store.Get(ctx, "1001"); got == nil || got.SeoTitle != "Concurrent" {
+		t.Errorf("Expected the concurrent write to be kept, got %+v", got)
+	}
+}
+
+func TestMirrorStore(t *testing.T) {
+	primary, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	bucket := newFakeS3()
+	mirror := NewS3StoreWithClient(bucket, "news")
+	testStore(t, NewMirrorStore(primary, map[string]Store{"r2": mirror}))
+
+	ctx := context.Background()
+	mirrored, err := mirror.List(ctx, Query{})
+	if err != nil || len(mirrored) != 2 {
+		t.Fatalf("Expected the mirror to hold the 2 remaining items, got %d, %v", len(mirrored), err)
+	}
+	if got, _ := mirror.Get(ctx, "1002"); got == nil || got.SeoTitle != "Derby ends in a draw" {
+		t.Errorf("Expected the update to reach the mirror, got %+v", got)
+	}
+
+	// A failing mirror does not fail the write
+	bucket.fail = true
+	store := NewMirrorStore(primary, map[string]Store{"r2": mirror})
+	if err := store.Save(ctx, &models.NewsItem{ID: "1004", CreatedAt: time.Now()}); err != nil {
+		t.Errorf("Expected the save to succeed despite the mirror, got %v", err)
+	}
+	if _, err := primary.Get(ctx, "1004"); err != nil {
+		t.Errorf("Expected the primary to hold the item, got %v", err)
+	}
+}
+
+func TestStoreQueries(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	items := []*models.NewsItem{
+		{ID: "2001", Category: "economy", Tags: []string{"Inflation"}, Source: "aa", Image: "a.jpg",
+			CreatedAt: base, PublishedAt: base.Add(3 * time.Hour)},
+		{ID: "2002", Category: "sports", Tags: []string{"football"}, Source: "trt", Language: "en",
+			CreatedAt: base.Add(time.Hour), PublishedAt: base.Add(time.Hour)},
+		{ID: "2003", Category: "economy", Tags: []string{"lira"}, Source: "aa", Image: "c.jpg", Language: "tr",
+			Status: models.StatusInReview, CreatedAt: base.Add(24 * time.Hour)},
+	}
+	yes, no := true, false
+	cases := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"tag ignores case", Query{Tag: "inflation"}, "2001"},
+		{"source", Query{Source: "aa"}, "2003,2001"},
+		{"language defaults to English", Query{Language: "en"}, "2002,2001"},
+		{"has image", Query{HasImage: &yes}, "2003,2001"},
+		{"has no image", Query{HasImage: &no}, "2002"},
+		{"published counts items without status", Query{Status: models.StatusPublished}, "2002,2001"},
+		{"in review", Query{Status: models.StatusInReview}, "2003"},
+		{"created range", Query{CreatedFrom: base.Add(time.Hour), CreatedTo: base.Add(2 * time.Hour)}, "2002"},
+		{"published range", Query{PublishedFrom: base.Add(2 * time.Hour)}, "2003,2001"},
+		{"oldest first", Query{Ascending: true}, "2001,2002,2003"},
+		{"category oldest first", Query{Category: "economy", Ascending: true}, "2001,2003"},
+		{"by publication", Query{Sort: SortPublished}, "2003,2001,2002"},
+		{"by publication paged", Query{Sort: SortPublished, Ascending: true, Offset: 1, Limit: 1}, "2001"},
+		{"filtered page", Query{Source: "aa", Offset: 1, Limit: 1}, "2001"},
+	}
+
+	stores := map[string]func(t *testing.T) Store{
+		"files": func(t *testing.T) Store {
+			store, err := NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			return store
+		},
+		"indexed": func(t *testing.T) Store {
+			store, err := NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			idx, err := OpenIndex(filepath.Join(t.TempDir(), "index.db"))
+			if err != nil {
+				t.Fatalf("Failed to open index: %v", err)
+			}
+			t.Cleanup(func() { idx.Close() })
+			store.SetIndex(idx)
+			return store
+		},
+		"s3": func(t *testing.T) Store {
+			return NewS3StoreWithClient(newFakeS3(), "news")
+		},
+		"indexed s3": func(t *testing.T) Store {
+			return newIndexedS3Store(t, newFakeS3())
+		},
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			for _, item := range items {
+				if err := store.Save(ctx, item); err != nil {
+					t.Fatalf("Save(%s) failed: %v", item.ID, err)
+				}
+			}
+			for _, c := range cases {
+				got, err := store.List(ctx, c.q)
+				if err != nil {
+					t.Fatalf("%s: List failed: %v", c.name, err)
+				}
+				ids := make([]string, len(got))
+				for i, item := range got {
+					ids[i] = item.ID
+				}
+				if strings.Join(ids, ",") != c.want {
+					t.Errorf("%s: Expected %s, got %s", c.name, c.want, strings.Join(ids, ","))
+				}
+			}
+		})
+	}
+}
+
+func TestStoreCursor(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		q    Query
+		want string
+	}{
+		{Query{}, "3004,3003,3002,3001"},
+		{Query{Ascending: true}, "3001,3002,3003,3004"},
+		{Query{Sort: SortPublished}, "3001,3004,3003,3002"},
+	}
+	for name, indexed := range map[string]bool{"files": false, "indexed": true} {
+		t.Run(name, func(t *testing.T) {
+			for _, c := range cases {
+				store := mustFileStore(t, indexed)
+				// 3002 and 3003 share a creation time and are ordered by ID
+				for _, item := range []*models.NewsItem{
+					{ID: "3001", CreatedAt: base, PublishedAt: base.Add(5 * time.Hour)},
+					{ID: "3002", CreatedAt: base.Add(time.Hour)},
+					{ID: "3003", CreatedAt: base.Add(time.Hour)},
+					{ID: "3004", CreatedAt: base.Add(2 * time.Hour)},
+				} {
+					if err := store.Save(ctx, item); err != nil {
+						t.Fatalf("Save failed: %v", err)
+					}
+				}
+
+				var walked []string
+				q := c.q
+				q.Limit = 2
+				for {
+					page, err := store.List(ctx, q)
+					if err != nil {
+						t.Fatalf("List failed: %v", err)
+					}
+					for _, item := range page {
+						walked = append(walked, item.ID)
+					}
+					if len(page) < q.Limit {
+						break
+					}
+					cursor := CursorAt(page[len(page)-1], q)
+					q.After = &cursor
+
+					// A newer item arriving mid-walk does not shift the next page
+					if len(walked) == 2 && !q.Ascending {
+						if err := store.Save(ctx, &models.NewsItem{ID: "3100", CreatedAt: base.Add(9 * time.Hour)}); err != nil {
+							t.Fatalf("Save failed: %v", err)
+						}
+					}
+				}
+				if got := strings.Join(walked, ","); got != c.want {
+					t.Errorf("Expected the walk %s, got %s", c.want, got)
+				}
+			}
+		})
+	}
+
+	store := mustFileStore(t, true)
+	for _, id := range []string{"1", "2", "3"} {
+		if err := store.Save(ctx, &models.NewsItem{ID: id, CreatedAt: base}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if n, err := store.Count(ctx, Query{Limit: 1, Offset: 2}); err != nil || n != 3 {
+		t.Errorf("Expected a total of 3 regardless of paging, got %d, %v", n, err)
+	}
+	if n, err := store.Count(ctx, Query{Tag: "none"}); err != nil || n != 0 {
+		t.Errorf("Expected no items with the tag, got %d, %v", n, err)
+	}
+}
+
+func mustFileStore(t *testing.T, indexed bool) *FileStore {
+	t.Helper()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if indexed {
+		idx, err := OpenIndex(filepath.Join(t.TempDir(), "index.db"))
+		if err != nil {
+			t.Fatalf("Failed to open index: %v", err)
+		}
+		t.Cleanup(func() { idx.Close() })
+		store.SetIndex(idx)
+	}
+	return store
+}
+
+func TestFileStoreQuarantinesCorruptFiles(t *testing.T) {
+	ctx := context.Background()
+	for name, indexed := range map[string]bool{"files": false, "indexed": true} {
+		t.Run(name, func(t *testing.T) {
+			store := mustFileStore(t, indexed)
+			base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+			for i, id := range []string{"4001", "4002", "4003"} {
+				if err := store.Save(ctx, &models.NewsItem{ID: id, CreatedAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+			// Truncate one file as a crash during a plain write would
+			broken, err := store.find("4002")
+			if err != nil {
+				t.Fatalf("find failed: %v", err)
+			}
+			if err := os.WriteFile(broken, []byte(`{"id": "40`), 0644); err != nil {
+				t.Fatalf("Failed to corrupt file: %v", err)
+			}
+
+			items, err := store.List(ctx, Query{})
+			if err != nil || len(items) != 2 {
+				t.Fatalf("Expected the 2 intact items, got %d, %v", len(items), err)
+			}
+			if _, err := os.Stat(broken); !os.IsNotExist(err) {
+				t.Errorf("Expected the corrupt file to be moved, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(store.root, quarantineDir, filepath.Base(broken))); err != nil {
+				t.Errorf("Expected the corrupt file in quarantine, got %v", err)
+			}
+			if _, err := store.Get(ctx, "4002"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for the quarantined item, got %v", err)
+			}
+			if n, err := store.Count(ctx, Query{}); err != nil || n != 2 {
+				t.Errorf("Expected a total of 2 after quarantine, got %d, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestFileStoreQuarantinesUnderWriteLock(t *testing.T) {
+	ctx := context.Background()
+	store := mustFileStore(t, false)
+	if err := store.Save(ctx, &models.NewsItem{ID: "4001", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	broken, _ := store.find("4001")
+	if err := os.WriteFile(broken, []byte(`{"id": "40`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+
+	// Another reader holds the lock, so the file is only moved once it is done
+	store.mu.RLock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.List(ctx, Query{})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := os.Stat(broken); err != nil {
+		t.Errorf("Expected the corrupt file to stay while the store is read, got %v", err)
+	}
+	store.mu.RUnlock()
+	<-done
+	if _, err := os.Stat(broken); !os.IsNotExist(err) {
+		t.Errorf("Expected the corrupt file to be moved, got %v", err)
+	}
+}
+
+func TestFileStoreRejectsDuplicateIDs(t *testing.T) {
+	ctx := context.Background()
+	for name, indexed := range map[string]bool{"files": false, "indexed": true} {
+		t.Run(name, func(t *testing.T) {
+			store := mustFileStore(t, indexed)
+			if err := store.Save(ctx, &models.NewsItem{ID: "6001", SeoTitle: "First", CreatedAt: time.Now()}); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			err := store.Save(ctx, &models.NewsItem{ID: "6001", SeoTitle: "Second", CreatedAt: time.Now()})
+			if !errors.Is(err, ErrExists) {
+				t.Fatalf("Expected ErrExists for a stored ID, got %v", err)
+			}
+			items, err := store.List(ctx, Query{})
+			if err != nil || len(items) != 1 || items[0].SeoTitle != "First" {
+				t.Errorf("Expected only the first item, got %v, %v", items, err)
+			}
+		})
+	}
+}
+
+func TestFileStoreWrites(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	item := &models.NewsItem{ID: "5001", CreatedAt: time.Now()}
+	if err := store.Save(ctx, item); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Temporary files of an interrupted write are neither listed nor kept
+	leftover := filepath.Join(filepath.Dir(item.FilePath), ".1_5002.json.tmp-123")
+	if err := os.WriteFile(leftover, []byte(`{"id":`), 0644); err != nil {
+		t.Fatalf("Failed to write temporary file: %v", err)
+	}
+	if items, err := store.List(ctx, Query{}); err != nil || len(items) != 1 {
+		t.Errorf("Expected only the saved item, got %d, %v", len(items), err)
+	}
+	if _, err := NewFileStore(dir); err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be removed on open, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(item.FilePath))
+	if len(entries) != 1 {
+		t.Errorf("Expected just the item file, got %d entries", len(entries))
+	}
+}
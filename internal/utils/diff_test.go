@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	a := "Rates held\nInflation slows\nLira steady"
+	b := "Rates held\nInflation eases\nLira steady\nMarkets calm"
+	want := strings.Join([]string{
+		"  Rates held",
+		"- Inflation slows",
+		"+ Inflation eases",
+		"  Lira steady",
+		"+ Markets calm",
+	}, "\n")
+	if got := strings.Join(LineDiff(a, b), "\n"); got != want {
+		t.Errorf("Expected diff\n%s\ngot\n%s", want, got)
+	}
+
+	if got := LineDiff("same", "same"); len(got) != 1 || got[0] != "  same" {
+		t.Errorf("Expected one unchanged line, got %q", got)
+	}
+	if got := LineDiff("", "new"); len(got) != 1 || got[0] != "+ new" {
+		t.Errorf("Expected one added line, got %q", got)
+	}
+}